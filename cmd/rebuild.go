@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/go-kit/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/repository"
+	"github.com/Bipolar-Penguin/bff-website/pkg/service"
+)
+
+var rebuildSummaryCmd = &cobra.Command{
+	Use:   "rebuild-summary",
+	Short: "Regenerate the sessions summary read model",
+	Long:  "Regenerate the sessions_summary collection from the trading sessions and bids",
+	Run: func(cmd *cobra.Command, args []string) {
+		rebuildSummary()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rebuildSummaryCmd)
+}
+
+func rebuildSummary() {
+	var logger log.Logger
+	{
+		logger = log.NewLogfmtLogger(log.NewSyncWriter(os.Stdout))
+		logger = log.With(logger, "ts", log.DefaultTimestampUTC)
+		logger.Log("app", os.Args[0], "event", "rebuilding sessions summary")
+	}
+
+	mongoURL := viper.GetString("mongo-url")
+	if mongoURL == "" {
+		logger.Log("error", "mongo-url argument was not provided")
+		os.Exit(1)
+	}
+
+	aliasSecret := viper.GetString("alias-secret")
+	if aliasSecret == "" {
+		logger.Log("error", "alias-secret argument was not provided")
+		os.Exit(1)
+	}
+
+	rep, err := repository.MakeRepositories(mongoURL, log.With(logger, "module", "repository"))
+	if err != nil {
+		os.Exit(1)
+	}
+
+	// the rebuild publishes no events, so it needs no broker
+	svc := service.NewService(rep, nil, aliasSecret, log.With(logger, "module", "service"))
+
+	if err := svc.RebuildSummaries(); err != nil {
+		logger.Log("error", err)
+		os.Exit(1)
+	}
+
+	logger.Log("event", "sessions summary rebuilt")
+}
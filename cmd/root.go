@@ -7,13 +7,16 @@ import (
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/go-kit/log"
+	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
-	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+	"github.com/Bipolar-Penguin/bff-website/pkg/pubsub"
 	"github.com/Bipolar-Penguin/bff-website/pkg/repository"
+	"github.com/Bipolar-Penguin/bff-website/pkg/scheduler"
 	"github.com/Bipolar-Penguin/bff-website/pkg/service"
 	"github.com/Bipolar-Penguin/bff-website/pkg/transport/amqp"
 	httptransport "github.com/Bipolar-Penguin/bff-website/pkg/transport/http"
@@ -21,12 +24,26 @@ import (
 
 const (
 	deafaultHTTPPort int = 8000
+
+	defaultRabbitmqExchange = "trading"
+
+	schedulerInterval = time.Second
+	relayInterval     = 250 * time.Millisecond
+
+	// eventReplaySize is how many events are kept for event streams resuming
+	// with Last-Event-ID
+	eventReplaySize = 1024
+	// eventDedupSize is how many event IDs are remembered to deliver the
+	// events coming both from this replica and from RabbitMQ only once
+	eventDedupSize = 4096
 )
 
 var (
-	cfgHTTPPort   int
-	cfgMongoURL   string
-	cfgRabbimqURL string
+	cfgHTTPPort         int
+	cfgMongoURL         string
+	cfgRabbimqURL       string
+	cfgRabbitmqExchange string
+	cfgAliasSecret      string
 )
 
 var rootCmd = &cobra.Command{
@@ -48,10 +65,14 @@ func init() {
 	rootCmd.PersistentFlags().IntVar(&cfgHTTPPort, "http-port", deafaultHTTPPort, "http port to connect to")
 	rootCmd.PersistentFlags().StringVar(&cfgMongoURL, "mongo-url", "", "mongo URL")
 	rootCmd.PersistentFlags().StringVar(&cfgMongoURL, "rabbitmq-url", "", "rabbitmq URL")
+	rootCmd.PersistentFlags().StringVar(&cfgRabbitmqExchange, "rabbitmq-exchange-name", defaultRabbitmqExchange, "rabbitmq topic exchange the events are published to")
+	rootCmd.PersistentFlags().StringVar(&cfgAliasSecret, "alias-secret", "", "secret key of the aliases suppliers are shown by")
 
 	viper.BindPFlag("http-port", rootCmd.PersistentFlags().Lookup("http-port"))
 	viper.BindPFlag("mongo-url", rootCmd.PersistentFlags().Lookup("mongo-url"))
 	viper.BindPFlag("rabbitmq-url", rootCmd.PersistentFlags().Lookup("rabbitmq-url"))
+	viper.BindPFlag("rabbitmq-exchange-name", rootCmd.PersistentFlags().Lookup("rabbitmq-exchange-name"))
+	viper.BindPFlag("alias-secret", rootCmd.PersistentFlags().Lookup("alias-secret"))
 }
 
 func initConfig() {
@@ -87,14 +108,23 @@ func run() { // nolint:funlen
 		logger.Log("error", "rabbitmq-url argument was not provided")
 		os.Exit(1)
 	}
+	rabbitmqExchange := viper.GetString("rabbitmq-exchange-name")
+	if rabbitmqExchange == "" {
+		logger.Log("error", "rabbitmq-exchange-name argument was not provided")
+		os.Exit(1)
+	}
+	aliasSecret := viper.GetString("alias-secret")
+	if aliasSecret == "" {
+		logger.Log("error", "alias-secret argument was not provided")
+		os.Exit(1)
+	}
 
 	// Broker declaration
 	var amqpBroker *amqp.RabbitBroker
 	{
 		logger := log.With(logger, "module", "transport.amqp")
 
-		amqpBroker = amqp.NewRabbitBroker(rabbitmqURL, logger)
-		amqpBroker.PublishEvent(domain.Event{GUID: "foobar"})
+		amqpBroker = amqp.NewRabbitBroker(rabbitmqURL, rabbitmqExchange, logger)
 	}
 
 	// Repositories declaration
@@ -108,10 +138,28 @@ func run() { // nolint:funlen
 		}
 	}
 
+	// Local subscribers of the events, e.g. WebSocket and SSE clients. They
+	// get the events of this replica directly once they are committed and
+	// those of the other replicas through RabbitMQ.
+	hub := pubsub.NewHub(eventReplaySize)
+	local := pubsub.NewDedup(hub, eventDedupSize)
+
+	consumeCtx, stopConsuming := context.WithCancel(context.Background())
+	defer stopConsuming()
+
+	go amqpBroker.Consume(consumeCtx, local)
+
 	// Services declaration
 	var svc *service.Service
 	{
-		svc = service.NewService(rep, amqpBroker)
+		logger := log.With(logger, "module", "service")
+
+		svc = service.NewService(rep, local, aliasSecret, logger)
+
+		if err := svc.RepairSummaries(); err != nil {
+			logger.Log("error", err)
+			os.Exit(1)
+		}
 	}
 
 	// HTTP server declaration
@@ -119,8 +167,38 @@ func run() { // nolint:funlen
 	{
 		logger := log.With(logger, "module", "http.transport")
 
-		srv = httptransport.NewHttpServer(httpPort, logger, svc)
+		srv = httptransport.NewHttpServer(httpPort, logger, svc, hub)
+	}
+	// holder identifies this replica in leases
+	hostname, _ := os.Hostname()
+	holder := hostname + "-" + uuid.NewString()
+
+	// Scheduler declaration
+	var sched *scheduler.Scheduler
+	{
+		logger := log.With(logger, "module", "scheduler")
+
+		sched = scheduler.NewScheduler(svc, rep.Lease, holder, schedulerInterval, logger)
+	}
+
+	// Outbox relay declaration
+	var relay *amqp.Relay
+	{
+		logger := log.With(logger, "module", "transport.amqp.relay")
+
+		relay = amqp.NewRelay(amqpBroker, rep.Outbox, rep.Lease, holder, relayInterval, logger)
 	}
+
+	schedCtx, stopScheduler := context.WithCancel(context.Background())
+	defer stopScheduler()
+
+	go sched.Run(schedCtx)
+
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	defer stopRelay()
+
+	go relay.Run(relayCtx)
+
 	idleConnsClosed := make(chan struct{})
 
 	go func() {
@@ -130,11 +208,19 @@ func run() { // nolint:funlen
 
 		logger.Log("event", "got os shutdown signal")
 
+		stopScheduler()
+		stopRelay()
+		stopConsuming()
+
 		if err := srv.Shutdown(context.Background()); err != nil {
 			logger.Log("error", err)
 			os.Exit(1)
 		}
 
+		if err := amqpBroker.Close(); err != nil {
+			logger.Log("error", err)
+		}
+
 		close(idleConnsClosed)
 		logger.Log("event", "server stopped")
 	}()
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+const (
+	// TieBreakEarliest means several suppliers offered the winning price and
+	// the earliest bid won.
+	TieBreakEarliest = "earliest_bid"
+)
+
+// Award records the outcome of a closed session or of one of its lots.
+type Award struct {
+	ID               string      `json:"id" bson:"_id"`
+	TradingSessionID string      `json:"trading_session_id" bson:"trading_session_id"`
+	LotID            string      `json:"lot_id,omitempty" bson:"lot_id,omitempty"`
+	Winner           TradingBid  `json:"winner" bson:"winner"`
+	RunnerUp         *TradingBid `json:"runner_up,omitempty" bson:"runner_up,omitempty"`
+	// Price is what the winner is paid, it differs from the winning bid in
+	// second-price auctions.
+	Price          int       `json:"price" bson:"price"`
+	MaxPrice       int       `json:"max_price" bson:"max_price"`
+	SavingsPercent float64   `json:"savings_percent" bson:"savings_percent"`
+	TieBreak       string    `json:"tie_break,omitempty" bson:"tie_break,omitempty"`
+	TiedBids       int       `json:"tied_bids,omitempty" bson:"tied_bids,omitempty"`
+	Date           time.Time `json:"date" bson:"date"`
+}
@@ -1,8 +1,27 @@
 package domain
 
+import "time"
+
+const (
+	EventActionUpdate   = "update"
+	EventActionExtended = "extended"
+	EventActionStatus   = "status"
+	EventActionAwarded  = "awarded"
+	EventActionAccepted = "accepted"
+
+	EventActionProxyBid       = "proxy_bid"
+	EventActionProxyExhausted = "proxy_exhausted"
+)
+
 type Event struct {
-	GUID    string `json:"guid"`
-	Action  string `json:"action"`
-	Amount  int    `json:"amount"`
-	EventID string `json:"event_id"`
+	// ID identifies the event itself, unlike EventID which is the session
+	// the event is about.
+	ID      string     `json:"id"`
+	GUID    string     `json:"guid"`
+	Action  string     `json:"action"`
+	Amount  int        `json:"amount"`
+	EventID string     `json:"event_id"`
+	LotID   string     `json:"lot_id,omitempty"`
+	End     *time.Time `json:"end,omitempty"`
+	Status  string     `json:"status,omitempty"`
 }
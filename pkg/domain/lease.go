@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// Lease grants exclusive right to run a job to a single holder. Token grows
+// every time the lease changes hands and serves as a fencing token.
+type Lease struct {
+	Name      string    `json:"name" bson:"_id"`
+	Holder    string    `json:"holder" bson:"holder"`
+	Token     int64     `json:"token" bson:"token"`
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"`
+}
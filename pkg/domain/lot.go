@@ -0,0 +1,35 @@
+package domain
+
+// Lot is a line item of a trading session that can be bid on separately.
+// Prices are in kopecks and are given for the whole Quantity.
+type Lot struct {
+	ID       string  `json:"id" bson:"id"`
+	Title    string  `json:"title" bson:"title"`
+	Quantity float64 `json:"quantity" bson:"quantity"`
+	Unit     string  `json:"unit" bson:"unit"`
+	MaxPrice int     `json:"max_price" bson:"max_price"`
+	MinPrice int     `json:"min_price" bson:"min_price"`
+	// CurrentPrice, LeaderID and Version mirror the fields of TradingSession
+	// for the best bid on the lot.
+	CurrentPrice int    `json:"current_price" bson:"current_price"`
+	LeaderID     string `json:"-" bson:"leader_id"`
+	Version      int    `json:"version" bson:"version"`
+}
+
+// BestPrice returns the price the next bid on the lot has to beat.
+func (l Lot) BestPrice() int {
+	if l.CurrentPrice == 0 {
+		return l.MaxPrice
+	}
+
+	return l.CurrentPrice
+}
+
+// FloorPrice returns the lowest price a bid on the lot may reach.
+func (l Lot) FloorPrice() int {
+	if l.MinPrice < 1 {
+		return 1
+	}
+
+	return l.MinPrice
+}
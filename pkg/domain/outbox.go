@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// OutboxMessage is an event waiting to be relayed to the broker. It is
+// written together with the change the event is about.
+type OutboxMessage struct {
+	ID          string    `json:"id" bson:"_id"`
+	Event       Event     `json:"event" bson:"event"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+	Attempts    int       `json:"attempts" bson:"attempts"`
+	NextAttempt time.Time `json:"next_attempt" bson:"next_attempt"`
+	LastError   string    `json:"last_error,omitempty" bson:"last_error,omitempty"`
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// ProxyBid bids on behalf of a supplier one step at a time down to Floor.
+type ProxyBid struct {
+	ID               string    `json:"id" bson:"_id"`
+	TradingSessionID string    `json:"trading_session_id" bson:"trading_session_id"`
+	UserID           string    `json:"user_id" bson:"user_id"`
+	Floor            int       `json:"floor" bson:"floor"`
+	Active           bool      `json:"active" bson:"active"`
+	Date             time.Time `json:"date" bson:"date"`
+}
@@ -0,0 +1,10 @@
+package domain
+
+// SessionHit is a trading session found by a text query. Snippets are HTML
+// with the matched words wrapped in <mark>.
+type SessionHit struct {
+	Session            TradingSession `json:"session"`
+	Score              float64        `json:"score"`
+	TitleSnippet       string         `json:"title_snippet"`
+	DescriptionSnippet string         `json:"description_snippet,omitempty"`
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// SessionSummary is the live state of a trading session as shown on its page.
+// Leader is an alias that does not disclose the supplier.
+type SessionSummary struct {
+	BestPrice     int       `json:"best_price"`
+	Leader        string    `json:"leader,omitempty"`
+	Participants  int       `json:"participants"`
+	Bids          int       `json:"bids"`
+	TimeRemaining int64     `json:"time_remaining"`
+	ServerTime    time.Time `json:"server_time"`
+}
+
+// SessionDetail is a trading session with its live summary.
+type SessionDetail struct {
+	TradingSession
+	Summary SessionSummary `json:"summary"`
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// SessionView is the read model of a trading session used for listings. It
+// is kept up to date on every bid and status change, so that listing does not
+// touch the bids.
+type SessionView struct {
+	TradingSession `bson:",inline"`
+	BestPrice      int        `json:"best_price" bson:"best_price"`
+	Leader         string     `json:"leader,omitempty" bson:"leader,omitempty"`
+	BidCount       int        `json:"bid_count" bson:"bid_count"`
+	LastBidAt      *time.Time `json:"last_bid_at,omitempty" bson:"last_bid_at,omitempty"`
+}
+
+// BidStats sums up the bids of a trading session.
+type BidStats struct {
+	TradingSessionID string    `bson:"_id"`
+	Count            int       `bson:"count"`
+	LastBidAt        time.Time `bson:"last_bid_at"`
+}
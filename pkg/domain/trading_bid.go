@@ -3,8 +3,12 @@ package domain
 import "time"
 
 type TradingBid struct {
-	TradingSessionID string    `json:"trading_session_id" bson:"trading_session_id"`
-	UserID           string    `json:"user_id" bson:"user_id"`
-	Bid              int       `json:"bid" bson:"bid"`
-	Date             time.Time `json:"date" bson:"date"`
+	TradingSessionID string `json:"trading_session_id" bson:"trading_session_id"`
+	// LotID is empty for bids on the whole session.
+	LotID  string    `json:"lot_id,omitempty" bson:"lot_id,omitempty"`
+	UserID string    `json:"user_id" bson:"user_id"`
+	Bid    int       `json:"bid" bson:"bid"`
+	Date   time.Time `json:"date" bson:"date"`
+	// Sealed marks the single hidden bid a supplier may make in a sealed session.
+	Sealed bool `json:"-" bson:"sealed,omitempty"`
 }
@@ -2,16 +2,232 @@ package domain
 
 import "time"
 
+type SessionStatus string
+
+const (
+	SessionDraft     SessionStatus = "draft"
+	SessionPublished SessionStatus = "published"
+	SessionActive    SessionStatus = "active"
+	SessionClosed    SessionStatus = "closed"
+	SessionAwarded   SessionStatus = "awarded"
+	SessionCancelled SessionStatus = "cancelled"
+	SessionSuspended SessionStatus = "suspended"
+	// SessionFailed is a closed session nobody bid on, there is nothing to award.
+	SessionFailed SessionStatus = "failed"
+)
+
+var sessionTransitions = map[SessionStatus][]SessionStatus{
+	SessionDraft:     {SessionPublished, SessionCancelled},
+	SessionPublished: {SessionActive, SessionCancelled},
+	SessionActive:    {SessionClosed, SessionSuspended, SessionCancelled},
+	SessionSuspended: {SessionActive, SessionClosed, SessionCancelled},
+	SessionClosed:    {SessionAwarded, SessionFailed},
+}
+
+// CanTransitionTo reports whether a session may move from s to next.
+func (s SessionStatus) CanTransitionTo(next SessionStatus) bool {
+	for _, status := range sessionTransitions[s] {
+		if status == next {
+			return true
+		}
+	}
+
+	return false
+}
+
+type AuctionType string
+
+const (
+	// AuctionOpen is a descending auction where every bid is visible.
+	AuctionOpen AuctionType = "open"
+	// AuctionSealedFirstPrice hides the bids until close, the lowest bid wins
+	// at its own price.
+	AuctionSealedFirstPrice AuctionType = "sealed_first_price"
+	// AuctionSealedSecondPrice hides the bids until close, the lowest bid wins
+	// at the price of the runner-up.
+	AuctionSealedSecondPrice AuctionType = "sealed_second_price"
+	// AuctionDutch lowers the offered price on a clock, the first supplier to
+	// accept it wins.
+	AuctionDutch AuctionType = "dutch"
+)
+
+type BidStepType string
+
+const (
+	BidStepPercent BidStepType = "percent"
+	BidStepFixed   BidStepType = "fixed"
+	BidStepRange   BidStepType = "range"
+)
+
+// DefaultBidStep is applied to sessions stored without a step policy.
+var DefaultBidStep = BidStep{Type: BidStepPercent, Percent: 1}
+
+// BidStep describes how much a single bid lowers the price of a session.
+// Amount, Min and Max are in kopecks, Percent is taken from MaxPrice.
+type BidStep struct {
+	Type    BidStepType `json:"type" bson:"type"`
+	Percent float64     `json:"percent,omitempty" bson:"percent,omitempty"`
+	Amount  int         `json:"amount,omitempty" bson:"amount,omitempty"`
+	Min     int         `json:"min,omitempty" bson:"min,omitempty"`
+	Max     int         `json:"max,omitempty" bson:"max,omitempty"`
+}
+
+// Bounds returns the smallest and the largest step allowed for the given max
+// price. A percent step is never below one kopeck.
+func (b BidStep) Bounds(maxPrice int) (int, int) {
+	switch b.Type {
+	case BidStepFixed:
+		return b.Amount, b.Amount
+	case BidStepRange:
+		return b.Min, b.Max
+	default:
+		step := int(float64(maxPrice) * b.Percent / 100)
+		if step < 1 {
+			step = 1
+		}
+
+		return step, step
+	}
+}
+
+// ExtensionRule moves the end of a session forward by Extend minutes when a bid
+// lands within the last Window minutes. A zero HardEnd means no cap.
+type ExtensionRule struct {
+	Window  int       `json:"window" bson:"window"`
+	Extend  int       `json:"extend" bson:"extend"`
+	HardEnd time.Time `json:"hard_end" bson:"hard_end"`
+}
+
 type TradingSession struct {
-	ID          string `json:"id" bson:"_id"`
-	Status      string `json:"status" bson:"status"`
-	Title       string `json:"title" bson:"title"`
-	Description string `json:"description" bson:"description"`
-	MaxPrice    int    `json:"max_price" bson:"max_price"`
-	Date        struct {
+	ID          string        `json:"id" bson:"_id"`
+	Status      SessionStatus `json:"status" bson:"status"`
+	Title       string        `json:"title" bson:"title"`
+	Description string        `json:"description" bson:"description"`
+	AuctionType AuctionType   `json:"auction_type" bson:"auction_type"`
+	MaxPrice    int           `json:"max_price" bson:"max_price"`
+	MinPrice    int           `json:"min_price" bson:"min_price"`
+	BidStep     BidStep       `json:"bid_step" bson:"bid_step"`
+	// CurrentPrice and LeaderID hold the best bid of the session, Version is
+	// bumped on every accepted bid and guards concurrent bidding.
+	CurrentPrice int    `json:"current_price" bson:"current_price"`
+	LeaderID     string `json:"-" bson:"leader_id"`
+	Version      int    `json:"version" bson:"version"`
+	// Fence is the highest lease token a background job changed the session with.
+	Fence int64 `json:"-" bson:"fence"`
+	// Revision is bumped on every change of the session, bids on lots
+	// included, and orders the copies of the session in the read model.
+	Revision int `json:"-" bson:"revision"`
+	Date     struct {
 		Start time.Time `json:"start" bson:"start"`
 		End   time.Time `json:"end" bson:"end"`
 	} `json:"date" bson:"date"`
-	ImageURLs []string `json:"image_urls" bson:"image_urls"`
-	UserID    string   `json:"user_id" bson:"user_id"`
+	// Lots are optional, bids on the whole session are bids on all of them.
+	Lots      []Lot         `json:"lots,omitempty" bson:"lots,omitempty"`
+	Extension ExtensionRule `json:"extension" bson:"extension"`
+	ImageURLs []string      `json:"image_urls" bson:"image_urls"`
+	Region    string        `json:"region" bson:"region"`
+	UserID    string        `json:"user_id" bson:"user_id"`
+	CreatedAt time.Time     `json:"created_at" bson:"created_at"`
+}
+
+// StepPolicy returns the bid step of the session or DefaultBidStep if none was set.
+func (s TradingSession) StepPolicy() BidStep {
+	if s.BidStep.Type == "" {
+		return DefaultBidStep
+	}
+
+	return s.BidStep
+}
+
+// FloorPrice returns the lowest price a bid may reach.
+func (s TradingSession) FloorPrice() int {
+	if s.MinPrice < 1 {
+		return 1
+	}
+
+	return s.MinPrice
+}
+
+// BestPrice returns the price the next bid has to beat.
+func (s TradingSession) BestPrice() int {
+	if s.CurrentPrice == 0 {
+		return s.MaxPrice
+	}
+
+	return s.CurrentPrice
+}
+
+// ExtendedEnd returns the new end of the session after a bid made at bidTime
+// and whether the session has to be extended at all.
+func (s TradingSession) ExtendedEnd(bidTime time.Time) (time.Time, bool) {
+	rule := s.Extension
+	if rule.Window <= 0 || rule.Extend <= 0 {
+		return s.Date.End, false
+	}
+
+	if bidTime.Before(s.Date.End.Add(-time.Duration(rule.Window) * time.Minute)) {
+		return s.Date.End, false
+	}
+
+	end := s.Date.End.Add(time.Duration(rule.Extend) * time.Minute)
+	if !rule.HardEnd.IsZero() && end.After(rule.HardEnd) {
+		end = rule.HardEnd
+	}
+
+	if !end.After(s.Date.End) {
+		return s.Date.End, false
+	}
+
+	return end, true
+}
+
+// IsOpen reports whether the session accepts bids at the given moment.
+func (s TradingSession) IsOpen(now time.Time) bool {
+	return s.Status == SessionActive && !now.Before(s.Date.Start) && now.Before(s.Date.End)
+}
+
+// Type returns the auction type of the session, AuctionOpen if none was set.
+func (s TradingSession) Type() AuctionType {
+	if s.AuctionType == "" {
+		return AuctionOpen
+	}
+
+	return s.AuctionType
+}
+
+// IsSealed reports whether the bids of the session are hidden until close.
+func (s TradingSession) IsSealed() bool {
+	return s.Type() == AuctionSealedFirstPrice || s.Type() == AuctionSealedSecondPrice
+}
+
+// ClockPrice returns the price offered by a dutch auction at the given moment.
+// The price goes down from MaxPrice by the smallest allowed step, evenly over
+// the session window, and reaches FloorPrice at its end.
+func (s TradingSession) ClockPrice(now time.Time) int {
+	step, _ := s.StepPolicy().Bounds(s.MaxPrice)
+	if step < 1 || !now.After(s.Date.Start) {
+		return s.MaxPrice
+	}
+
+	steps := (s.MaxPrice - s.FloorPrice()) / step
+	window := s.Date.End.Sub(s.Date.Start)
+
+	if steps < 1 || window <= 0 || !now.Before(s.Date.End) {
+		return s.MaxPrice - steps*step
+	}
+
+	passed := int(int64(now.Sub(s.Date.Start)) * int64(steps) / int64(window))
+
+	return s.MaxPrice - passed*step
+}
+
+// Lot returns the lot of the session with the given ID.
+func (s TradingSession) Lot(lotID string) (Lot, bool) {
+	for _, lot := range s.Lots {
+		if lot.ID == lotID {
+			return lot, true
+		}
+	}
+
+	return Lot{}, false
 }
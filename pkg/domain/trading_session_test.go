@@ -0,0 +1,165 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionStatusCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to SessionStatus
+		want     bool
+	}{
+		{SessionDraft, SessionPublished, true},
+		{SessionPublished, SessionActive, true},
+		{SessionActive, SessionSuspended, true},
+		{SessionSuspended, SessionActive, true},
+		{SessionActive, SessionClosed, true},
+		{SessionClosed, SessionAwarded, true},
+		{SessionClosed, SessionFailed, true},
+		{SessionDraft, SessionActive, false},
+		{SessionClosed, SessionActive, false},
+		{SessionAwarded, SessionClosed, false},
+		{SessionFailed, SessionAwarded, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+			t.Errorf("%s.CanTransitionTo(%s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestBidStepBounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		step     BidStep
+		maxPrice int
+		min, max int
+	}{
+		{"percent", BidStep{Type: BidStepPercent, Percent: 1}, 1500000, 15000, 15000},
+		{"fractional percent", BidStep{Type: BidStepPercent, Percent: 0.5}, 999, 4, 4},
+		{"percent below a kopeck", BidStep{Type: BidStepPercent, Percent: 1}, 50, 1, 1},
+		{"fixed", BidStep{Type: BidStepFixed, Amount: 500}, 1500000, 500, 500},
+		{"range", BidStep{Type: BidStepRange, Min: 100, Max: 1000}, 1500000, 100, 1000},
+		{"no type is percent", BidStep{Percent: 2}, 10000, 200, 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			min, max := tt.step.Bounds(tt.maxPrice)
+			if min != tt.min || max != tt.max {
+				t.Errorf("Bounds(%d) = %d, %d, want %d, %d", tt.maxPrice, min, max, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestTradingSessionExtendedEnd(t *testing.T) {
+	end := time.Date(2022, 5, 20, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		rule     ExtensionRule
+		bid      time.Time
+		want     time.Time
+		extended bool
+	}{
+		{
+			name: "no rule",
+			bid:  end.Add(-time.Minute),
+			want: end,
+		},
+		{
+			name: "before the window",
+			rule: ExtensionRule{Window: 5, Extend: 3},
+			bid:  end.Add(-10 * time.Minute),
+			want: end,
+		},
+		{
+			name:     "at the start of the window",
+			rule:     ExtensionRule{Window: 5, Extend: 3},
+			bid:      end.Add(-5 * time.Minute),
+			want:     end.Add(3 * time.Minute),
+			extended: true,
+		},
+		{
+			name:     "within the window",
+			rule:     ExtensionRule{Window: 5, Extend: 3},
+			bid:      end.Add(-time.Second),
+			want:     end.Add(3 * time.Minute),
+			extended: true,
+		},
+		{
+			name:     "capped by the hard end",
+			rule:     ExtensionRule{Window: 5, Extend: 3, HardEnd: end.Add(2 * time.Minute)},
+			bid:      end.Add(-time.Minute),
+			want:     end.Add(2 * time.Minute),
+			extended: true,
+		},
+		{
+			name: "hard end reached",
+			rule: ExtensionRule{Window: 5, Extend: 3, HardEnd: end},
+			bid:  end.Add(-time.Minute),
+			want: end,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var session TradingSession
+			session.Date.End = end
+			session.Extension = tt.rule
+
+			got, extended := session.ExtendedEnd(tt.bid)
+			if !got.Equal(tt.want) || extended != tt.extended {
+				t.Errorf("ExtendedEnd() = %v, %v, want %v, %v", got, extended, tt.want, tt.extended)
+			}
+		})
+	}
+}
+
+func TestTradingSessionClockPrice(t *testing.T) {
+	start := time.Date(2022, 5, 20, 12, 0, 0, 0, time.UTC)
+
+	dutch := func(minPrice int, step BidStep) TradingSession {
+		session := TradingSession{
+			AuctionType: AuctionDutch,
+			MaxPrice:    10000,
+			MinPrice:    minPrice,
+			BidStep:     step,
+		}
+		session.Date.Start = start
+		session.Date.End = start.Add(50 * time.Minute)
+
+		return session
+	}
+
+	fixed := BidStep{Type: BidStepFixed, Amount: 100}
+
+	tests := []struct {
+		name    string
+		session TradingSession
+		now     time.Time
+		want    int
+	}{
+		{"before the start", dutch(5000, fixed), start.Add(-time.Minute), 10000},
+		{"at the start", dutch(5000, fixed), start, 10000},
+		{"one step", dutch(5000, fixed), start.Add(time.Minute), 9900},
+		{"between steps", dutch(5000, fixed), start.Add(90 * time.Second), 9900},
+		{"last step", dutch(5000, fixed), start.Add(50*time.Minute - time.Second), 5100},
+		{"at the end", dutch(5000, fixed), start.Add(50 * time.Minute), 5000},
+		{"after the end", dutch(5000, fixed), start.Add(time.Hour), 5000},
+		{"floor between steps", dutch(5050, fixed), start.Add(time.Hour), 5100},
+		{"no step", dutch(5000, BidStep{Type: BidStepFixed}), start.Add(time.Minute), 10000},
+		{"percent below a kopeck", dutch(5000, BidStep{Type: BidStepPercent, Percent: 0.001}), start.Add(time.Minute), 9900},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.session.ClockPrice(tt.now); got != tt.want {
+				t.Errorf("ClockPrice() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package pubsub
+
+import (
+	"sync"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+// Dedup passes events on to a publisher once, dropping repeated event IDs.
+// Only the last IDs are remembered, so a repeat arriving much later gets
+// through again.
+type Dedup struct {
+	next Publisher
+
+	mu   sync.Mutex
+	seen map[string]struct{}
+	// ring holds the remembered IDs in the order they were seen
+	ring []string
+	pos  int
+}
+
+// NewDedup creates a Dedup remembering up to size event IDs.
+func NewDedup(next Publisher, size int) *Dedup {
+	return &Dedup{
+		next: next,
+		seen: make(map[string]struct{}, size),
+		ring: make([]string, size),
+	}
+}
+
+func (d *Dedup) PublishEvent(event domain.Event) {
+	if !d.first(event.ID) {
+		return
+	}
+
+	d.next.PublishEvent(event)
+}
+
+// first remembers the ID and reports whether it was new. Events without an ID
+// are always new.
+func (d *Dedup) first(id string) bool {
+	if id == "" || len(d.ring) == 0 {
+		return true
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.seen[id]; ok {
+		return false
+	}
+
+	delete(d.seen, d.ring[d.pos])
+	d.ring[d.pos] = id
+	d.pos = (d.pos + 1) % len(d.ring)
+	d.seen[id] = struct{}{}
+
+	return true
+}
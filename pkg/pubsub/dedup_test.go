@@ -0,0 +1,46 @@
+package pubsub
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+type recorder struct {
+	ids []string
+}
+
+func (r *recorder) PublishEvent(event domain.Event) {
+	r.ids = append(r.ids, event.ID)
+}
+
+func TestDedup(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+		in   []string
+		want []string
+	}{
+		{"distinct", 4, []string{"a", "b", "c"}, []string{"a", "b", "c"}},
+		{"repeated", 4, []string{"a", "b", "a", "b", "a"}, []string{"a", "b"}},
+		{"without ID", 4, []string{"", "", "a", ""}, []string{"", "", "a", ""}},
+		{"forgotten", 2, []string{"a", "b", "c", "a", "c"}, []string{"a", "b", "c", "a"}},
+		{"no memory", 0, []string{"a", "a"}, []string{"a", "a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var next recorder
+
+			dedup := NewDedup(&next, tt.size)
+			for _, id := range tt.in {
+				dedup.PublishEvent(domain.Event{ID: id})
+			}
+
+			if !reflect.DeepEqual(next.ids, tt.want) {
+				t.Errorf("passed %v, want %v", next.ids, tt.want)
+			}
+		})
+	}
+}
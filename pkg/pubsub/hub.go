@@ -0,0 +1,237 @@
+package pubsub
+
+import (
+	"sync"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+// TopicAll receives the events of every session.
+const TopicAll = "*"
+
+// Publisher delivers domain events somewhere.
+type Publisher interface {
+	PublishEvent(event domain.Event)
+}
+
+// Hub routes events to the subscriptions of this process by session ID.
+// Publishing never blocks: a subscription whose buffer is full is dropped as
+// a slow consumer. The last events are kept for subscribers resuming after a
+// reconnect.
+type Hub struct {
+	mu     sync.Mutex
+	topics map[string]map[*Subscription]struct{}
+
+	// history is a ring of the last published events, next is where the
+	// following one goes
+	history []domain.Event
+	next    int
+	full    bool
+}
+
+// NewHub creates a hub keeping up to replay events for resuming subscribers.
+func NewHub(replay int) *Hub {
+	return &Hub{
+		topics:  make(map[string]map[*Subscription]struct{}),
+		history: make([]domain.Event, replay),
+	}
+}
+
+// Subscription receives the events of the topics it was added to.
+type Subscription struct {
+	hub    *Hub
+	events chan domain.Event
+	done   chan struct{}
+	once   sync.Once
+	// dropped is set before done is closed for a slow consumer
+	dropped bool
+
+	mu     sync.Mutex
+	topics map[string]struct{}
+}
+
+// Subscribe creates a subscription buffering up to buffer events.
+func (h *Hub) Subscribe(buffer int) *Subscription {
+	return &Subscription{
+		hub:    h,
+		events: make(chan domain.Event, buffer),
+		done:   make(chan struct{}),
+		topics: make(map[string]struct{}),
+	}
+}
+
+// SubscribeAfter subscribes to the topics and returns the kept events of the
+// topics published after the event with the given ID. If that event is no
+// longer kept, every kept event of the topics is returned. No event is
+// missed or repeated between the returned ones and the subscription.
+func (h *Hub) SubscribeAfter(buffer int, lastID string, topics ...string) (*Subscription, []domain.Event) {
+	sub := h.Subscribe(buffer)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	for _, topic := range topics {
+		sub.add(topic)
+	}
+
+	kept := h.kept()
+
+	for i, event := range kept {
+		if event.ID == lastID {
+			kept = kept[i+1:]
+			break
+		}
+	}
+
+	var missed []domain.Event
+
+	for _, event := range kept {
+		if sub.follows(event) {
+			missed = append(missed, event)
+		}
+	}
+
+	return sub, missed
+}
+
+func (h *Hub) PublishEvent(event domain.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.history) != 0 {
+		h.history[h.next] = event
+		h.next = (h.next + 1) % len(h.history)
+		h.full = h.full || h.next == 0
+	}
+
+	// a subscription to both the session and TopicAll gets the event once
+	delivered := make(map[*Subscription]bool)
+
+	for _, topic := range []string{event.EventID, TopicAll} {
+		for sub := range h.topics[topic] {
+			if !delivered[sub] {
+				sub.deliver(event)
+				delivered[sub] = true
+			}
+		}
+	}
+}
+
+// Events returns the channel the events are delivered to.
+func (s *Subscription) Events() <-chan domain.Event {
+	return s.events
+}
+
+// Done is closed when the subscription is dropped or closed.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Dropped reports whether the subscription was dropped as a slow consumer
+// rather than closed. It is meaningful once Done is closed.
+func (s *Subscription) Dropped() bool {
+	select {
+	case <-s.done:
+		return s.dropped
+	default:
+		return false
+	}
+}
+
+// Add subscribes to the topics.
+func (s *Subscription) Add(topics ...string) {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, topic := range topics {
+		s.add(topic)
+	}
+}
+
+// Remove unsubscribes from the topics.
+func (s *Subscription) Remove(topics ...string) {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, topic := range topics {
+		s.hub.remove(topic, s)
+		delete(s.topics, topic)
+	}
+}
+
+// Close unsubscribes from every topic and closes Done.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for topic := range s.topics {
+		s.hub.remove(topic, s)
+	}
+
+	s.topics = make(map[string]struct{})
+	s.once.Do(func() { close(s.done) })
+}
+
+// add is called with the hub and the subscription locked.
+func (s *Subscription) add(topic string) {
+	if s.hub.topics[topic] == nil {
+		s.hub.topics[topic] = make(map[*Subscription]struct{})
+	}
+
+	s.hub.topics[topic][s] = struct{}{}
+	s.topics[topic] = struct{}{}
+}
+
+// follows is called with the subscription locked.
+func (s *Subscription) follows(event domain.Event) bool {
+	_, all := s.topics[TopicAll]
+	_, session := s.topics[event.EventID]
+
+	return all || session
+}
+
+// deliver is called with the hub locked.
+func (s *Subscription) deliver(event domain.Event) {
+	select {
+	case <-s.done:
+	case s.events <- event:
+	default:
+		// slow consumer, the owner notices Done and cleans up with Close
+		s.once.Do(func() {
+			s.dropped = true
+			close(s.done)
+		})
+	}
+}
+
+// kept returns the kept events, the oldest first. It is called with the hub
+// locked.
+func (h *Hub) kept() []domain.Event {
+	if !h.full {
+		return append([]domain.Event(nil), h.history[:h.next]...)
+	}
+
+	return append(append([]domain.Event(nil), h.history[h.next:]...), h.history[:h.next]...)
+}
+
+// remove is called with the hub locked.
+func (h *Hub) remove(topic string, sub *Subscription) {
+	subs := h.topics[topic]
+	delete(subs, sub)
+
+	if len(subs) == 0 {
+		delete(h.topics, topic)
+	}
+}
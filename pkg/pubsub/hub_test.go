@@ -0,0 +1,175 @@
+package pubsub
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+// drain returns the IDs of the events buffered by the subscription.
+func drain(sub *Subscription) []string {
+	var ids []string
+
+	for {
+		select {
+		case event := <-sub.Events():
+			ids = append(ids, event.ID)
+		default:
+			return ids
+		}
+	}
+}
+
+func ids(events []domain.Event) []string {
+	var res []string
+	for _, event := range events {
+		res = append(res, event.ID)
+	}
+
+	return res
+}
+
+func TestHubRouting(t *testing.T) {
+	events := []domain.Event{
+		{ID: "1", EventID: "s1"},
+		{ID: "2", EventID: "s2"},
+		{ID: "3", EventID: "s1"},
+	}
+
+	tests := []struct {
+		name   string
+		topics []string
+		want   []string
+	}{
+		{"nothing", nil, nil},
+		{"one session", []string{"s1"}, []string{"1", "3"}},
+		{"two sessions", []string{"s1", "s2"}, []string{"1", "2", "3"}},
+		{"all", []string{TopicAll}, []string{"1", "2", "3"}},
+		{"session and all", []string{"s2", TopicAll}, []string{"1", "2", "3"}},
+		{"unknown session", []string{"s3"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := NewHub(0)
+
+			sub := hub.Subscribe(len(events))
+			sub.Add(tt.topics...)
+
+			for _, event := range events {
+				hub.PublishEvent(event)
+			}
+
+			if got := drain(sub); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHubRemove(t *testing.T) {
+	hub := NewHub(0)
+
+	sub := hub.Subscribe(4)
+	sub.Add("s1", "s2")
+	sub.Remove("s1")
+
+	hub.PublishEvent(domain.Event{ID: "1", EventID: "s1"})
+	hub.PublishEvent(domain.Event{ID: "2", EventID: "s2"})
+
+	if got, want := drain(sub), []string{"2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestHubSlowConsumer(t *testing.T) {
+	hub := NewHub(0)
+
+	slow := hub.Subscribe(1)
+	slow.Add("s1")
+
+	closed := hub.Subscribe(1)
+	closed.Add("s1")
+	closed.Close()
+
+	hub.PublishEvent(domain.Event{ID: "1", EventID: "s1"})
+
+	select {
+	case <-slow.Done():
+		t.Fatal("dropped with room in the buffer")
+	default:
+	}
+
+	hub.PublishEvent(domain.Event{ID: "2", EventID: "s1"})
+
+	select {
+	case <-slow.Done():
+	default:
+		t.Fatal("not dropped with a full buffer")
+	}
+
+	if !slow.Dropped() {
+		t.Error("Dropped() = false for a slow consumer")
+	}
+
+	if closed.Dropped() {
+		t.Error("Dropped() = true for a closed subscription")
+	}
+
+	if got := drain(closed); got != nil {
+		t.Errorf("closed subscription got %v", got)
+	}
+}
+
+func TestHubSubscribeAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		lastID string
+		topics []string
+		want   []string
+	}{
+		{"after a kept event", "3", []string{"s1"}, []string{"4", "6"}},
+		{"after the last event", "6", []string{"s1"}, nil},
+		{"after an event no longer kept", "1", []string{"s1"}, []string{"3", "4", "6"}},
+		{"without an event", "", []string{TopicAll}, []string{"3", "4", "5", "6"}},
+		{"other session", "3", []string{"s2"}, []string{"5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := NewHub(4)
+
+			for i, session := range []string{"s1", "s2", "s1", "s1", "s2", "s1"} {
+				hub.PublishEvent(domain.Event{ID: string(rune('1' + i)), EventID: session})
+			}
+
+			sub, missed := hub.SubscribeAfter(4, tt.lastID, tt.topics...)
+			defer sub.Close()
+
+			if got := ids(missed); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("missed %v, want %v", got, tt.want)
+			}
+
+			hub.PublishEvent(domain.Event{ID: "7", EventID: "s1"})
+			hub.PublishEvent(domain.Event{ID: "8", EventID: "s2"})
+
+			var want []string
+			for _, topic := range tt.topics {
+				if topic == "s1" || topic == TopicAll {
+					want = append(want, "7")
+				}
+			}
+
+			for _, topic := range tt.topics {
+				if topic == "s2" || topic == TopicAll {
+					want = append(want, "8")
+				}
+			}
+
+			if got := drain(sub); !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v after subscribing, want %v", got, want)
+			}
+		})
+	}
+}
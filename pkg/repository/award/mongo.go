@@ -0,0 +1,55 @@
+package award
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+type mongoRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMongoRepository(coll *mongo.Collection) *mongoRepository {
+	return &mongoRepository{coll}
+}
+
+func (m *mongoRepository) FindMany(tradingSessionID string) ([]domain.Award, error) {
+	var awards []domain.Award
+
+	cursor, err := m.coll.Find(context.Background(), bson.M{"trading_session_id": tradingSessionID})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(context.Background(), &awards); err != nil {
+		return nil, err
+	}
+
+	return awards, nil
+}
+
+func (m *mongoRepository) Save(ctx context.Context, award domain.Award) (domain.Award, error) {
+	// the ID is derived from the session and the lot, so that awarding the
+	// same session twice after a restart overwrites the first award
+	award.ID = award.TradingSessionID
+	if award.LotID != "" {
+		award.ID += "/" + award.LotID
+	}
+
+	_, err := m.coll.ReplaceOne(
+		ctx,
+		bson.M{"_id": award.ID},
+		award,
+		options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		return award, err
+	}
+
+	return award, nil
+}
@@ -0,0 +1,14 @@
+package award
+
+import (
+	"context"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+type Repository interface {
+	FindMany(tradingSessionID string) ([]domain.Award, error)
+	// Save stores the award, replacing the one already written for the same
+	// session and lot.
+	Save(ctx context.Context, award domain.Award) (domain.Award, error)
+}
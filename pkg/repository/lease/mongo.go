@@ -0,0 +1,77 @@
+package lease
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+type mongoRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMongoRepository(coll *mongo.Collection) *mongoRepository {
+	return &mongoRepository{coll}
+}
+
+func (m *mongoRepository) Acquire(name, holder string, ttl time.Duration) (domain.Lease, error) {
+	var lease domain.Lease
+
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	// renew the lease we already hold, the token stays the same
+	err := m.coll.FindOneAndUpdate(
+		context.Background(),
+		bson.M{"_id": name, "holder": holder},
+		bson.M{"$set": bson.M{"expires_at": expiresAt}},
+		opts,
+	).Decode(&lease)
+	if err != mongo.ErrNoDocuments {
+		return lease, err
+	}
+
+	// take over an expired lease, the token is bumped to fence the old holder
+	err = m.coll.FindOneAndUpdate(
+		context.Background(),
+		bson.M{"_id": name, "expires_at": bson.M{"$lte": now}},
+		bson.M{
+			"$set": bson.M{"holder": holder, "expires_at": expiresAt},
+			"$inc": bson.M{"token": 1},
+		},
+		opts,
+	).Decode(&lease)
+	if err != mongo.ErrNoDocuments {
+		return lease, err
+	}
+
+	lease = domain.Lease{Name: name, Holder: holder, Token: 1, ExpiresAt: expiresAt}
+
+	_, err = m.coll.InsertOne(context.Background(), lease)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.Lease{}, ErrNotHeld
+	}
+
+	if err != nil {
+		return domain.Lease{}, err
+	}
+
+	return lease, nil
+}
+
+func (m *mongoRepository) Release(name, holder string) error {
+	_, err := m.coll.UpdateOne(
+		context.Background(),
+		bson.M{"_id": name, "holder": holder},
+		bson.M{"$set": bson.M{"expires_at": time.Time{}}},
+	)
+
+	return err
+}
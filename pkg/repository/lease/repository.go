@@ -0,0 +1,18 @@
+package lease
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+// ErrNotHeld is returned when the lease belongs to somebody else.
+var ErrNotHeld = errors.New("lease is held by another holder")
+
+type Repository interface {
+	// Acquire takes the lease for holder or renews it if holder already has it.
+	Acquire(name, holder string, ttl time.Duration) (domain.Lease, error)
+	// Release gives the lease up if holder has it.
+	Release(name, holder string) error
+}
@@ -0,0 +1,59 @@
+package lease
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/log"
+)
+
+// ttlFactor is how many ticks a lease survives without renewal.
+const ttlFactor = 5
+
+// Runner runs a periodic job on the one replica holding the named lease. The
+// lease is renewed on every tick and passed over when its holder stops ticking.
+type Runner struct {
+	leases   Repository
+	name     string
+	holder   string
+	interval time.Duration
+	logger   log.Logger
+}
+
+func NewRunner(leases Repository, name, holder string, interval time.Duration, logger log.Logger) *Runner {
+	return &Runner{leases, name, holder, interval, logger}
+}
+
+// Run calls job on every tick the lease is held, with the fencing token of the
+// lease, until ctx is cancelled and gives the lease up on exit.
+func (r *Runner) Run(ctx context.Context, job func(now time.Time, token int64)) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.tick(time.Now(), job)
+
+		select {
+		case <-ctx.Done():
+			if err := r.leases.Release(r.name, r.holder); err != nil {
+				r.logger.Log("error", err)
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Runner) tick(now time.Time, job func(now time.Time, token int64)) {
+	l, err := r.leases.Acquire(r.name, r.holder, ttlFactor*r.interval)
+	if err == ErrNotHeld {
+		return
+	}
+
+	if err != nil {
+		r.logger.Log("error", err)
+		return
+	}
+
+	job(now, l.Token)
+}
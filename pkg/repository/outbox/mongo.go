@@ -0,0 +1,99 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+type mongoRepository struct {
+	coll *mongo.Collection
+	dead *mongo.Collection
+}
+
+func NewMongoRepository(coll, dead *mongo.Collection) *mongoRepository {
+	r := &mongoRepository{coll, dead}
+	r.createIndexes()
+	return r
+}
+
+func (m *mongoRepository) createIndexes() error {
+	_, err := m.coll.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "next_attempt", Value: 1}, {Key: "created_at", Value: 1}},
+	})
+
+	return err
+}
+
+func (m *mongoRepository) Add(ctx context.Context, event domain.Event) error {
+	now := time.Now()
+
+	_, err := m.coll.InsertOne(ctx, domain.OutboxMessage{
+		ID:          event.ID,
+		Event:       event,
+		CreatedAt:   now,
+		NextAttempt: now,
+	})
+
+	return err
+}
+
+func (m *mongoRepository) FindDue(t time.Time, limit int) ([]domain.OutboxMessage, error) {
+	var messages []domain.OutboxMessage
+
+	var opts options.FindOptions
+	opts.SetSort(bson.D{{Key: "created_at", Value: 1}})
+	opts.SetLimit(int64(limit))
+
+	cursor, err := m.coll.Find(context.Background(), bson.M{"next_attempt": bson.M{"$lte": t}}, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(context.Background(), &messages); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func (m *mongoRepository) Delete(messageID string) error {
+	_, err := m.coll.DeleteOne(context.Background(), bson.M{"_id": messageID})
+	return err
+}
+
+func (m *mongoRepository) Retry(messageID string, next time.Time, lastError string) error {
+	_, err := m.coll.UpdateOne(
+		context.Background(),
+		bson.M{"_id": messageID},
+		bson.M{
+			"$set": bson.M{"next_attempt": next, "last_error": lastError},
+			"$inc": bson.M{"attempts": 1},
+		},
+	)
+
+	return err
+}
+
+func (m *mongoRepository) DeadLetter(message domain.OutboxMessage, lastError string) error {
+	message.Attempts++
+	message.LastError = lastError
+
+	// a message left in the outbox by a failed delete is moved again
+	_, err := m.dead.ReplaceOne(
+		context.Background(),
+		bson.M{"_id": message.ID},
+		message,
+		options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		return err
+	}
+
+	return m.Delete(message.ID)
+}
@@ -0,0 +1,23 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+type Repository interface {
+	// Add writes the event to the outbox.
+	Add(ctx context.Context, event domain.Event) error
+	// FindDue returns messages whose next attempt is not after t, the oldest
+	// first.
+	FindDue(t time.Time, limit int) ([]domain.OutboxMessage, error)
+	// Delete removes a relayed message.
+	Delete(messageID string) error
+	// Retry counts a failed attempt and postpones the message until next.
+	Retry(messageID string, next time.Time, lastError string) error
+	// DeadLetter moves a message that cannot be relayed to the dead-letter
+	// store.
+	DeadLetter(message domain.OutboxMessage, lastError string) error
+}
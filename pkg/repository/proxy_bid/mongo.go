@@ -0,0 +1,81 @@
+package proxy_bid
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+	"github.com/google/uuid"
+)
+
+type mongoRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMongoRepository(coll *mongo.Collection) *mongoRepository {
+	return &mongoRepository{coll}
+}
+
+func (m *mongoRepository) FindActive(tradingSessionID string) ([]domain.ProxyBid, error) {
+	var proxies []domain.ProxyBid
+
+	var opts options.FindOptions
+	opts.SetSort(bson.D{{Key: "floor", Value: 1}, {Key: "date", Value: 1}})
+
+	cursor, err := m.coll.Find(
+		context.Background(),
+		bson.M{"trading_session_id": tradingSessionID, "active": true},
+		&opts,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(context.Background(), &proxies); err != nil {
+		return nil, err
+	}
+
+	return proxies, nil
+}
+
+func (m *mongoRepository) Save(proxy domain.ProxyBid) (domain.ProxyBid, error) {
+	var existing domain.ProxyBid
+
+	err := m.coll.FindOne(
+		context.Background(),
+		bson.M{"trading_session_id": proxy.TradingSessionID, "user_id": proxy.UserID},
+	).Decode(&existing)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return proxy, err
+	}
+
+	proxy.ID = existing.ID
+	if proxy.ID == "" {
+		proxy.ID = uuid.NewString()
+	}
+
+	_, err = m.coll.ReplaceOne(
+		context.Background(),
+		bson.M{"_id": proxy.ID},
+		proxy,
+		options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		return proxy, err
+	}
+
+	return proxy, nil
+}
+
+func (m *mongoRepository) Deactivate(ctx context.Context, proxyID string) error {
+	_, err := m.coll.UpdateOne(
+		ctx,
+		bson.M{"_id": proxyID},
+		bson.M{"$set": bson.M{"active": false}},
+	)
+
+	return err
+}
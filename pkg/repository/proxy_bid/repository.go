@@ -0,0 +1,17 @@
+package proxy_bid
+
+import (
+	"context"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+type Repository interface {
+	// FindActive returns active proxies of the session, the lowest floor first
+	// and the earliest registered first among equal floors.
+	FindActive(tradingSessionID string) ([]domain.ProxyBid, error)
+	// Save registers a proxy, replacing the one the user already has in the session.
+	Save(domain.ProxyBid) (domain.ProxyBid, error)
+	// Deactivate stops the proxy.
+	Deactivate(ctx context.Context, proxyID string) error
+}
@@ -2,12 +2,19 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/Bipolar-Penguin/bff-website/pkg/repository/award"
+	"github.com/Bipolar-Penguin/bff-website/pkg/repository/lease"
+	"github.com/Bipolar-Penguin/bff-website/pkg/repository/outbox"
+	"github.com/Bipolar-Penguin/bff-website/pkg/repository/proxy_bid"
+	"github.com/Bipolar-Penguin/bff-website/pkg/repository/session_summary"
 	"github.com/Bipolar-Penguin/bff-website/pkg/repository/trading_bid"
 	"github.com/Bipolar-Penguin/bff-website/pkg/repository/trading_session"
 	"github.com/Bipolar-Penguin/bff-website/pkg/repository/user"
 	"github.com/go-kit/log"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -18,12 +25,29 @@ const (
 	userCollection           string = "users"
 	tradingSessionCollection string = "trading_sessions"
 	tradingBids              string = "trading_bids"
+	leaseCollection          string = "leases"
+	proxyBids                string = "proxy_bids"
+	awardCollection          string = "awards"
+	sessionSummaryCollection string = "sessions_summary"
+	outboxCollection         string = "outbox"
+	deadLetterCollection     string = "outbox_dead"
 )
 
+// ErrNoTransactions is returned for a mongo server that is neither a replica
+// set member nor a mongos.
+var ErrNoTransactions = errors.New("mongo does not support transactions, run it as a replica set")
+
 type Repositories struct {
 	User           user.Repository
 	TradingSession trading_session.Repository
 	TradingBid     trading_bid.Repository
+	Lease          lease.Repository
+	ProxyBid       proxy_bid.Repository
+	Award          award.Repository
+	SessionSummary session_summary.Repository
+	Outbox         outbox.Repository
+
+	client *mongo.Client
 }
 
 func MakeRepositories(mongoURL string, logger log.Logger) (*Repositories, error) {
@@ -44,6 +68,62 @@ func MakeRepositories(mongoURL string, logger log.Logger) (*Repositories, error)
 	r.User = user.NewMongoRepository(client.Database(tradingDatabase).Collection(userCollection))
 	r.TradingSession = trading_session.NewMongoRepository(client.Database(tradingDatabase).Collection(tradingSessionCollection))
 	r.TradingBid = trading_bid.NewMongoRepository(client.Database(tradingDatabase).Collection(tradingBids))
+	r.ProxyBid = proxy_bid.NewMongoRepository(client.Database(tradingDatabase).Collection(proxyBids))
+	r.SessionSummary = session_summary.NewMongoRepository(client.Database(tradingDatabase).Collection(sessionSummaryCollection))
+	r.Award = award.NewMongoRepository(client.Database(tradingDatabase).Collection(awardCollection))
+	r.Lease = lease.NewMongoRepository(client.Database(tradingDatabase).Collection(leaseCollection))
+	r.Outbox = outbox.NewMongoRepository(
+		client.Database(tradingDatabase).Collection(outboxCollection),
+		client.Database(tradingDatabase).Collection(deadLetterCollection),
+	)
+
+	r.client = client
+
+	transactions, err := supportsTransactions(client)
+	if err != nil {
+		logger.Log("error", err)
+		return nil, err
+	}
+
+	// changes and their outbox events must be written together
+	if !transactions {
+		logger.Log("error", ErrNoTransactions)
+		return nil, ErrNoTransactions
+	}
 
 	return r, nil
 }
+
+// Transaction runs fn in a transaction, fn passes ctx to the writes that must
+// be committed together. Every repository method taking a ctx joins the
+// transaction it carries. Repositories not backed by mongo, e.g. in tests, run
+// fn as is.
+func (r *Repositories) Transaction(fn func(ctx context.Context) error) error {
+	if r.client == nil {
+		return fn(context.Background())
+	}
+
+	return r.client.UseSession(context.Background(), func(sc mongo.SessionContext) error {
+		_, err := sc.WithTransaction(sc, func(sc mongo.SessionContext) (interface{}, error) {
+			return nil, fn(sc)
+		})
+
+		return err
+	})
+}
+
+// supportsTransactions reports whether the server is a replica set member or
+// a mongos.
+func supportsTransactions(client *mongo.Client) (bool, error) {
+	var res struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+
+	err := client.Database("admin").RunCommand(context.Background(), bson.D{{Key: "isMaster", Value: 1}}).Decode(&res)
+	if err != nil {
+		return false, err
+	}
+
+	return res.SetName != "" || res.Msg == "isdbgrid", nil
+}
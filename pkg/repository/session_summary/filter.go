@@ -0,0 +1,96 @@
+package session_summary
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+const (
+	SortEndDate = "end_date"
+	SortPrice   = "price"
+	SortCreated = "created"
+)
+
+// ErrInvalidCursor is returned when a page cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid page cursor")
+
+// Filter selects a page of sessions. Zero fields do not filter. Cursor is the
+// NextCursor of the previous page and must be used with the same Sort and Desc.
+type Filter struct {
+	Statuses []domain.SessionStatus
+	Region   string
+	MinPrice int
+	MaxPrice int
+	// From and To select sessions whose window overlaps them.
+	From   time.Time
+	To     time.Time
+	UserID string
+	Query  string
+	Sort   string
+	Desc   bool
+	Cursor string
+	Limit  int
+}
+
+// cursor points right after the last session of a page.
+type cursor struct {
+	Value int64  `json:"v"`
+	ID    string `json:"id"`
+}
+
+func (c cursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" {
+		return c, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// sortField returns the document field sessions are sorted by.
+func (f Filter) sortField() string {
+	switch f.Sort {
+	case SortPrice:
+		return "max_price"
+	case SortCreated:
+		return "created_at"
+	default:
+		return "date.end"
+	}
+}
+
+// sortValue returns the value of the sort field of the session as stored in a cursor.
+func (f Filter) sortValue(session domain.SessionView) int64 {
+	switch f.Sort {
+	case SortPrice:
+		return int64(session.MaxPrice)
+	case SortCreated:
+		return session.CreatedAt.UnixNano()
+	default:
+		return session.Date.End.UnixNano()
+	}
+}
+
+// cursorValue converts a cursor value back to the type of the sort field.
+func (f Filter) cursorValue(c cursor) interface{} {
+	if f.Sort == SortPrice {
+		return c.Value
+	}
+
+	return time.Unix(0, c.Value).UTC()
+}
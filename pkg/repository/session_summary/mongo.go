@@ -0,0 +1,200 @@
+package session_summary
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+type mongoRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMongoRepository(coll *mongo.Collection) *mongoRepository {
+	r := &mongoRepository{coll}
+	r.createIndexes()
+	return r
+}
+
+func (m *mongoRepository) createIndexes() error {
+	_, err := m.coll.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "date.end", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "max_price", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "date.end", Value: 1}}},
+		{Keys: bson.D{{Key: "region", Value: 1}, {Key: "date.end", Value: 1}}},
+		{
+			Keys: bson.D{{Key: "title", Value: "text"}, {Key: "description", Value: "text"}},
+			Options: options.Index().
+				SetName("text_ru").
+				SetDefaultLanguage("russian").
+				SetWeights(bson.M{"title": 5, "description": 1}),
+		},
+	})
+
+	return err
+}
+
+func (m *mongoRepository) FindPage(filter Filter) ([]domain.SessionView, string, error) {
+	var sessions []domain.SessionView
+
+	conditions := bson.A{}
+
+	if len(filter.Statuses) != 0 {
+		conditions = append(conditions, bson.M{"status": bson.M{"$in": filter.Statuses}})
+	}
+
+	if filter.Region != "" {
+		conditions = append(conditions, bson.M{"region": filter.Region})
+	}
+
+	if filter.UserID != "" {
+		conditions = append(conditions, bson.M{"user_id": filter.UserID})
+	}
+
+	if filter.MinPrice != 0 {
+		conditions = append(conditions, bson.M{"max_price": bson.M{"$gte": filter.MinPrice}})
+	}
+
+	if filter.MaxPrice != 0 {
+		conditions = append(conditions, bson.M{"max_price": bson.M{"$lte": filter.MaxPrice}})
+	}
+
+	if !filter.From.IsZero() {
+		conditions = append(conditions, bson.M{"date.end": bson.M{"$gte": filter.From}})
+	}
+
+	if !filter.To.IsZero() {
+		conditions = append(conditions, bson.M{"date.start": bson.M{"$lte": filter.To}})
+	}
+
+	if filter.Query != "" {
+		conditions = append(conditions, bson.M{"$text": bson.M{"$search": filter.Query, "$language": "russian"}})
+	}
+
+	order, cmp := 1, "$gt"
+	if filter.Desc {
+		order, cmp = -1, "$lt"
+	}
+
+	field := filter.sortField()
+
+	if filter.Cursor != "" {
+		c, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+
+		value := filter.cursorValue(c)
+		conditions = append(conditions, bson.M{"$or": bson.A{
+			bson.M{field: bson.M{cmp: value}},
+			bson.M{field: value, "_id": bson.M{cmp: c.ID}},
+		}})
+	}
+
+	query := bson.M{}
+	if len(conditions) != 0 {
+		query["$and"] = conditions
+	}
+
+	var opts options.FindOptions
+	opts.SetSort(bson.D{{Key: field, Value: order}, {Key: "_id", Value: order}})
+	// one extra session tells whether there is a next page
+	opts.SetLimit(int64(filter.Limit) + 1)
+
+	res, err := m.coll.Find(context.Background(), query, &opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := res.All(context.Background(), &sessions); err != nil {
+		return nil, "", err
+	}
+
+	if len(sessions) <= filter.Limit {
+		return sessions, "", nil
+	}
+
+	sessions = sessions[:filter.Limit]
+	last := sessions[len(sessions)-1]
+
+	return sessions, cursor{Value: filter.sortValue(last), ID: last.ID}.encode(), nil
+}
+
+func (m *mongoRepository) SaveSession(session domain.TradingSession, bestPrice int, leader string) error {
+	raw, err := bson.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	var fields bson.M
+	if err := bson.Unmarshal(raw, &fields); err != nil {
+		return err
+	}
+
+	delete(fields, "_id")
+	fields["best_price"] = bestPrice
+	fields["leader"] = leader
+
+	_, err = m.coll.UpdateOne(
+		context.Background(),
+		bson.M{"_id": session.ID, "$or": bson.A{
+			bson.M{"revision": bson.M{"$lte": session.Revision}},
+			bson.M{"revision": bson.M{"$exists": false}},
+		}},
+		bson.M{"$set": fields},
+		options.Update().SetUpsert(true),
+	)
+	// the upsert collides with a view of a newer version, which is fine
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+
+	return err
+}
+
+func (m *mongoRepository) FindAll() ([]domain.SessionView, error) {
+	var views []domain.SessionView
+
+	var opts options.FindOptions
+	opts.SetProjection(bson.M{"revision": 1, "bid_count": 1, "last_bid_at": 1})
+
+	cursor, err := m.coll.Find(context.Background(), bson.M{}, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(context.Background(), &views); err != nil {
+		return nil, err
+	}
+
+	return views, nil
+}
+
+func (m *mongoRepository) RecordBid(sessionID string, at time.Time) error {
+	_, err := m.coll.UpdateOne(
+		context.Background(),
+		bson.M{"_id": sessionID},
+		bson.M{
+			"$inc": bson.M{"bid_count": 1},
+			"$max": bson.M{"last_bid_at": at},
+		},
+	)
+
+	return err
+}
+
+func (m *mongoRepository) SetBidStats(stats domain.BidStats) error {
+	_, err := m.coll.UpdateOne(
+		context.Background(),
+		bson.M{"_id": stats.TradingSessionID},
+		bson.M{"$set": bson.M{"bid_count": stats.Count, "last_bid_at": stats.LastBidAt}},
+	)
+
+	return err
+}
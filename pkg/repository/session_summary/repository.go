@@ -0,0 +1,23 @@
+package session_summary
+
+import (
+	"time"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+type Repository interface {
+	// FindPage returns a page of sessions matching the filter and the cursor
+	// of the next page, empty on the last page.
+	FindPage(filter Filter) ([]domain.SessionView, string, error)
+	// SaveSession copies the session into its view together with the best
+	// price and the leader alias, keeping the bid counters. A view already
+	// built from a newer revision of the session is left alone.
+	SaveSession(session domain.TradingSession, bestPrice int, leader string) error
+	// FindAll returns every view with only its ID, revision and bid counters.
+	FindAll() ([]domain.SessionView, error)
+	// RecordBid counts a bid made at the given time.
+	RecordBid(sessionID string, at time.Time) error
+	// SetBidStats overwrites the bid counters of the view.
+	SetBidStats(stats domain.BidStats) error
+}
@@ -15,14 +15,76 @@ type mongoRepository struct {
 }
 
 func NewMongoRepository(coll *mongo.Collection) *mongoRepository {
-	return &mongoRepository{coll}
+	r := &mongoRepository{coll}
+	r.createIndexes()
+	return r
+}
+
+func (m *mongoRepository) createIndexes() error {
+	_, err := m.coll.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		// serves FindBest for the session and for each of its lots, bids on
+		// the whole session have no lot_id and are indexed under null
+		{Keys: bson.D{{Key: "trading_session_id", Value: 1}, {Key: "lot_id", Value: 1}, {Key: "bid", Value: 1}, {Key: "date", Value: 1}}},
+		{Keys: bson.D{{Key: "trading_session_id", Value: 1}, {Key: "date", Value: -1}}},
+		{
+			Keys: bson.D{{Key: "trading_session_id", Value: 1}, {Key: "user_id", Value: 1}},
+			Options: options.Index().
+				SetName("sealed_once").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"sealed": true}),
+		},
+	})
+
+	return err
 }
 
 func (m *mongoRepository) FindMany(tradingSessionID string) ([]domain.TradingBid, error) {
 	var bids []domain.TradingBid
 
 	var opts options.FindOptions
-	opts.SetSort(bson.M{"bid": 1})
+	opts.SetSort(bson.D{{Key: "bid", Value: 1}, {Key: "date", Value: 1}})
+
+	cursor, err := m.coll.Find(context.Background(), bson.M{"trading_session_id": tradingSessionID}, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(context.Background(), &bids); err != nil {
+		return nil, err
+	}
+
+	return bids, nil
+}
+
+func (m *mongoRepository) FindBest(tradingSessionID, lotID string) (domain.TradingBid, error) {
+	var bid domain.TradingBid
+
+	filter := bson.M{"trading_session_id": tradingSessionID, "lot_id": lotID}
+	if lotID == "" {
+		filter["lot_id"] = bson.M{"$exists": false}
+	}
+
+	opts := options.FindOne().SetSort(bson.D{{Key: "bid", Value: 1}, {Key: "date", Value: 1}})
+
+	err := m.coll.FindOne(context.Background(), filter, opts).Decode(&bid)
+	if err == mongo.ErrNoDocuments {
+		return bid, nil
+	}
+
+	if err != nil {
+		return bid, err
+	}
+
+	return bid, nil
+}
+
+func (m *mongoRepository) FindHistory(tradingSessionID string, offset, limit int) ([]domain.TradingBid, error) {
+	var bids []domain.TradingBid
+
+	var opts options.FindOptions
+	opts.SetSort(bson.M{"date": -1})
+	opts.SetSkip(int64(offset))
+	opts.SetLimit(int64(limit))
 
 	cursor, err := m.coll.Find(context.Background(), bson.M{"trading_session_id": tradingSessionID}, &opts)
 	if err != nil {
@@ -36,7 +98,55 @@ func (m *mongoRepository) FindMany(tradingSessionID string) ([]domain.TradingBid
 	return bids, nil
 }
 
-func (m *mongoRepository) Save(bid domain.TradingBid) error {
-	_, err := m.coll.InsertOne(context.Background(), bid)
+func (m *mongoRepository) CountBySession(tradingSessionID string) (int, error) {
+	count, err := m.coll.CountDocuments(context.Background(), bson.M{"trading_session_id": tradingSessionID})
+	return int(count), err
+}
+
+func (m *mongoRepository) CountParticipants(tradingSessionID string) (int, error) {
+	users, err := m.coll.Distinct(context.Background(), "user_id", bson.M{"trading_session_id": tradingSessionID})
+	return len(users), err
+}
+
+func (m *mongoRepository) Stats() ([]domain.BidStats, error) {
+	var stats []domain.BidStats
+
+	cursor, err := m.coll.Aggregate(context.Background(), mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":         "$trading_session_id",
+			"count":       bson.M{"$sum": 1},
+			"last_bid_at": bson.M{"$max": "$date"},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(context.Background(), &stats); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (m *mongoRepository) Exists(tradingSessionID, userID string) (bool, error) {
+	count, err := m.coll.CountDocuments(
+		context.Background(),
+		bson.M{"trading_session_id": tradingSessionID, "user_id": userID},
+		options.Count().SetLimit(1),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (m *mongoRepository) Save(ctx context.Context, bid domain.TradingBid) error {
+	_, err := m.coll.InsertOne(ctx, bid)
+	if bid.Sealed && mongo.IsDuplicateKeyError(err) {
+		return ErrSealedExists
+	}
+
 	return err
 }
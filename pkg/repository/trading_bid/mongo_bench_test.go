@@ -0,0 +1,110 @@
+//go:build mongo
+// +build mongo
+
+package trading_bid
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+// benchBids is the number of bids of the benchmarked session.
+const benchBids = 5000
+
+// benchRepository fills a scratch collection with the bids of one session,
+// spread over two lots and the whole session. It needs APP_MONGO_URL:
+//
+//	APP_MONGO_URL=mongodb://localhost go test -tags mongo -bench . ./pkg/repository/trading_bid/
+func benchRepository(b *testing.B) *mongoRepository {
+	b.Helper()
+
+	url := os.Getenv("APP_MONGO_URL")
+	if url == "" {
+		b.Skip("APP_MONGO_URL is not set")
+	}
+
+	ctx := context.Background()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(url))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	coll := client.Database("trading_bench").Collection("trading_bids_" + strconv.FormatInt(time.Now().UnixNano(), 10))
+
+	b.Cleanup(func() {
+		coll.Drop(ctx)
+		client.Disconnect(ctx)
+	})
+
+	r := NewMongoRepository(coll)
+
+	lots := []string{"", "lot-1", "lot-2"}
+	now := time.Now()
+
+	docs := make([]interface{}, 0, benchBids)
+	for i := 0; i < benchBids; i++ {
+		docs = append(docs, domain.TradingBid{
+			TradingSessionID: "session",
+			LotID:            lots[i%len(lots)],
+			UserID:           "user-" + strconv.Itoa(i%50),
+			Bid:              1000000 - i,
+			Date:             now.Add(time.Duration(i) * time.Millisecond),
+		})
+	}
+
+	if _, err := coll.InsertMany(ctx, docs); err != nil {
+		b.Fatal(err)
+	}
+
+	return r
+}
+
+func BenchmarkFindBest(b *testing.B) {
+	r := benchRepository(b)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := r.FindBest("session", ""); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFindBestLot(b *testing.B) {
+	r := benchRepository(b)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := r.FindBest("session", "lot-1"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkFindManyFirst is the lookup FindBest replaced: all bids of the
+// session are loaded to read the first one.
+func BenchmarkFindManyFirst(b *testing.B) {
+	r := benchRepository(b)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		bids, err := r.FindMany("session")
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		_ = bids[0]
+	}
+}
@@ -1,8 +1,32 @@
 package trading_bid
 
-import "github.com/Bipolar-Penguin/bff-website/pkg/domain"
+import (
+	"context"
+	"errors"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+// ErrSealedExists is returned when the user already made a sealed bid in the session.
+var ErrSealedExists = errors.New("sealed bid already submitted")
 
 type Repository interface {
+	// FindMany returns bids of the session, the lowest and then the earliest first.
 	FindMany(tradingSessionID string) ([]domain.TradingBid, error)
-	Save(domain.TradingBid) error
+	// FindBest returns the lowest and then the earliest bid on the lot or, if
+	// lotID is empty, on the whole session. It returns a zero bid if there is none.
+	FindBest(tradingSessionID, lotID string) (domain.TradingBid, error)
+	// FindHistory returns a page of bids of the session, the latest first.
+	FindHistory(tradingSessionID string, offset, limit int) ([]domain.TradingBid, error)
+	// CountBySession returns the number of bids in the session.
+	CountBySession(tradingSessionID string) (int, error)
+	// CountParticipants returns the number of distinct users who bid in the session.
+	CountParticipants(tradingSessionID string) (int, error)
+	// Stats sums up the bids of every session.
+	Stats() ([]domain.BidStats, error)
+	// Exists reports whether the user has bid in the session.
+	Exists(tradingSessionID, userID string) (bool, error)
+	// Save stores the bid. A second sealed bid of the user in the session gets
+	// ErrSealedExists.
+	Save(ctx context.Context, bid domain.TradingBid) error
 }
@@ -6,6 +6,7 @@ import (
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
 	"github.com/google/uuid"
@@ -17,10 +18,27 @@ type mongoRepository struct {
 
 func NewMongoRepository(coll *mongo.Collection) *mongoRepository {
 	r := &mongoRepository{coll}
+	r.createIndexes()
 	r.dummy()
 	return r
 }
 
+func (m *mongoRepository) createIndexes() error {
+	_, err := m.coll.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "date.end", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "date.start", Value: 1}}},
+		{
+			Keys: bson.D{{Key: "title", Value: "text"}, {Key: "description", Value: "text"}},
+			Options: options.Index().
+				SetName("text_ru").
+				SetDefaultLanguage("russian").
+				SetWeights(bson.M{"title": 5, "description": 1}),
+		},
+	})
+
+	return err
+}
+
 func (m *mongoRepository) Find(sessionID string) (domain.TradingSession, error) {
 	var session domain.TradingSession
 
@@ -38,28 +56,216 @@ func (m *mongoRepository) Find(sessionID string) (domain.TradingSession, error)
 	return session, nil
 }
 
-func (m *mongoRepository) Save(session domain.TradingSession) error {
-	var operations []mongo.WriteModel
+func (m *mongoRepository) Create(session domain.TradingSession) (domain.TradingSession, error) {
+	session.ID = uuid.NewString()
+	session.Version = 0
+	session.Revision = 0
+	session.CreatedAt = time.Now()
 
-	if session.ID != "" {
-		session.ID = uuid.NewString()
+	_, err := m.coll.InsertOne(context.Background(), session)
+	if err != nil {
+		return session, err
 	}
-	mdl := mongo.NewReplaceOneModel().
-		SetFilter(bson.M{"_id": session.ID}).
-		SetUpsert(true).
-		SetReplacement(session)
 
-	operations = append(operations, mdl)
+	return session, nil
+}
+
+func (m *mongoRepository) Update(session domain.TradingSession) (domain.TradingSession, error) {
+	version, revision := session.Version, session.Revision
+	session.Version++
+	session.Revision++
+
+	res, err := m.coll.ReplaceOne(
+		context.Background(),
+		bson.M{
+			"_id":      session.ID,
+			"version":  versionFilter(version),
+			"revision": versionFilter(revision),
+			"status":   session.Status,
+		},
+		session,
+	)
+	if err != nil {
+		return session, err
+	}
 
-	_, err := m.coll.BulkWrite(context.Background(), operations)
+	if res.MatchedCount != 0 {
+		return session, nil
+	}
 
+	count, err := m.coll.CountDocuments(context.Background(), bson.M{"_id": session.ID})
+	if err != nil {
+		return session, err
+	}
+
+	if count == 0 {
+		return session, ErrNotFound
+	}
+
+	return session, ErrVersionConflict
+}
+
+func (m *mongoRepository) UpdateBestBid(ctx context.Context, sessionID string, version int, bid domain.TradingBid, end time.Time) error {
+	res, err := m.coll.UpdateOne(
+		ctx,
+		bson.M{
+			"_id":      sessionID,
+			"version":  versionFilter(version),
+			"status":   domain.SessionActive,
+			"date.end": bson.M{"$gt": bid.Date},
+		},
+		bson.M{
+			"$set": bson.M{"current_price": bid.Bid, "leader_id": bid.UserID},
+			"$inc": bson.M{"version": 1, "revision": 1},
+			"$max": bson.M{"date.end": end},
+		},
+	)
 	if err != nil {
 		return err
 	}
 
+	if res.MatchedCount == 0 {
+		return ErrVersionConflict
+	}
+
 	return nil
 }
 
+func (m *mongoRepository) UpdateLotBestBid(ctx context.Context, sessionID, lotID string, version int, bid domain.TradingBid, end time.Time) error {
+	lotFilter := bson.M{"id": lotID, "version": versionFilter(version)}
+
+	res, err := m.coll.UpdateOne(
+		ctx,
+		bson.M{
+			"_id":      sessionID,
+			"status":   domain.SessionActive,
+			"date.end": bson.M{"$gt": bid.Date},
+			"lots":     bson.M{"$elemMatch": lotFilter},
+		},
+		bson.M{
+			"$set": bson.M{"lots.$.current_price": bid.Bid, "lots.$.leader_id": bid.UserID},
+			"$inc": bson.M{"lots.$.version": 1, "revision": 1},
+			"$max": bson.M{"date.end": end},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrVersionConflict
+	}
+
+	return nil
+}
+
+func (m *mongoRepository) SetWinner(ctx context.Context, sessionID, userID string, price int, fence int64) error {
+	filter := bson.M{"_id": sessionID}
+	update := bson.M{"current_price": price, "leader_id": userID}
+
+	applyFence(filter, update, fence)
+
+	res, err := m.coll.UpdateOne(
+		ctx,
+		filter,
+		bson.M{"$set": update, "$inc": bson.M{"revision": 1}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrStatusConflict
+	}
+
+	return nil
+}
+
+func (m *mongoRepository) UpdateStatus(ctx context.Context, sessionID string, from, to domain.SessionStatus, fence int64) error {
+	filter := bson.M{"_id": sessionID, "status": from}
+	update := bson.M{"status": to}
+
+	applyFence(filter, update, fence)
+
+	res, err := m.coll.UpdateOne(
+		ctx,
+		filter,
+		bson.M{"$set": update, "$inc": bson.M{"revision": 1}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrStatusConflict
+	}
+
+	return nil
+}
+
+func (m *mongoRepository) FindByStatus(status domain.SessionStatus) ([]domain.TradingSession, error) {
+	return m.findMany(bson.M{"status": status})
+}
+
+func (m *mongoRepository) FindStarting(t time.Time) ([]domain.TradingSession, error) {
+	return m.findMany(bson.M{"status": domain.SessionPublished, "date.start": bson.M{"$lte": t}})
+}
+
+func (m *mongoRepository) FindEnding(t time.Time) ([]domain.TradingSession, error) {
+	return m.findMany(bson.M{"status": domain.SessionActive, "date.end": bson.M{"$lte": t}})
+}
+
+func (m *mongoRepository) findMany(filter bson.M) ([]domain.TradingSession, error) {
+	var sessions []domain.TradingSession
+
+	cursor, err := m.coll.Find(context.Background(), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(context.Background(), &sessions); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (m *mongoRepository) Search(query string, statuses []domain.SessionStatus, limit int) ([]domain.SessionHit, error) {
+	var found []struct {
+		domain.TradingSession `bson:",inline"`
+		Score                 float64 `bson:"score"`
+	}
+
+	score := bson.M{"$meta": "textScore"}
+
+	var opts options.FindOptions
+	opts.SetProjection(bson.M{"score": score})
+	opts.SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: 1}})
+	opts.SetLimit(int64(limit))
+
+	filter := bson.M{"$text": bson.M{"$search": query, "$language": "russian"}}
+	if len(statuses) != 0 {
+		filter["status"] = bson.M{"$in": statuses}
+	}
+
+	cursor, err := m.coll.Find(context.Background(), filter, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(context.Background(), &found); err != nil {
+		return nil, err
+	}
+
+	hits := make([]domain.SessionHit, 0, len(found))
+
+	for _, f := range found {
+		hits = append(hits, domain.SessionHit{Session: f.TradingSession, Score: f.Score})
+	}
+
+	return hits, nil
+}
+
 func (m *mongoRepository) FindAll() ([]domain.TradingSession, error) {
 	var sessions []domain.TradingSession
 
@@ -79,13 +285,41 @@ func (m *mongoRepository) FindAll() ([]domain.TradingSession, error) {
 	return sessions, nil
 }
 
+// applyFence makes the update match only if the session was not changed with
+// a newer lease token, and records the token. A zero fence changes nothing.
+func applyFence(filter, update bson.M, fence int64) {
+	if fence == 0 {
+		return
+	}
+
+	filter["$or"] = bson.A{
+		bson.M{"fence": bson.M{"$lte": fence}},
+		bson.M{"fence": bson.M{"$exists": false}},
+	}
+	update["fence"] = fence
+}
+
+// versionFilter matches the given version or revision. Documents stored before
+// versioning have no such field at all and count as 0.
+func versionFilter(version int) interface{} {
+	if version == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+
+	return version
+}
+
 func (m *mongoRepository) dummy() error {
 	session := domain.TradingSession{
 		ID:          "1345497a-1f76-46a2-9561-b5fdf77b722e",
-		Status:      "active",
+		Status:      domain.SessionActive,
 		Title:       `Флорариум с суккулентами "Нежность S"`,
 		Description: "ГОСУДАРСТВЕННОЕ БЮДЖЕТНОЕ ПРОФЕССИОНАЛЬНОЕ ОБРАЗОВАТЕЛЬНОЕ УЧРЕЖДЕНИЕ ДЕПАРТАМЕНТА ЗДРАВООХРАНЕНИЯ ГОРОДА МОСКВЫ «МЕДИЦИНСКИЙ КОЛЛЕДЖ № 7»",
 		MaxPrice:    15000000,
+		BidStep: domain.BidStep{
+			Type:    domain.BidStepPercent,
+			Percent: 1,
+		},
 		Date: struct {
 			Start time.Time "json:\"start\" bson:\"start\""
 			End   time.Time "json:\"end\" bson:\"end\""
@@ -94,7 +328,9 @@ func (m *mongoRepository) dummy() error {
 			End:   time.Now().Add(time.Duration(6 * time.Hour)),
 		},
 		ImageURLs: []string{"https://zakupki.mos.ru/newapi/api/Core/Thumbnail/2119285468/140/140"},
+		Region:    "Москва",
 		UserID:    "",
+		CreatedAt: time.Now(),
 	}
 
 	_, err := m.coll.InsertOne(context.Background(), session)
@@ -1,9 +1,51 @@
 package trading_session
 
-import "github.com/Bipolar-Penguin/bff-website/pkg/domain"
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+var (
+	// ErrVersionConflict is returned when the session was changed since it was read.
+	ErrVersionConflict = errors.New("trading session version conflict")
+	// ErrNotFound is returned when there is no session with the given ID.
+	ErrNotFound = errors.New("trading session not found")
+	// ErrStatusConflict is returned when the session is no longer in the expected status.
+	ErrStatusConflict = errors.New("trading session status conflict")
+)
 
 type Repository interface {
 	FindAll() ([]domain.TradingSession, error)
 	Find(sessionID string) (domain.TradingSession, error)
-	Save(domain.TradingSession) error
+	// Search returns sessions in the given statuses matching a Russian text
+	// query, the most relevant first. Snippets of the hits are left empty.
+	Search(query string, statuses []domain.SessionStatus, limit int) ([]domain.SessionHit, error)
+	FindByStatus(status domain.SessionStatus) ([]domain.TradingSession, error)
+	// FindStarting returns published sessions whose start is not after t.
+	FindStarting(t time.Time) ([]domain.TradingSession, error)
+	// FindEnding returns active sessions whose end is not after t.
+	FindEnding(t time.Time) ([]domain.TradingSession, error)
+	// Create stores a new session under a freshly generated ID.
+	Create(domain.TradingSession) (domain.TradingSession, error)
+	// Update replaces a stored session if it is still at session.Version,
+	// session.Revision and in session.Status and returns it with both bumped.
+	// Every other change below bumps the revision as well.
+	Update(domain.TradingSession) (domain.TradingSession, error)
+	// UpdateBestBid stores bid as the best one of the session if the session
+	// is still active, at the given version and has not ended by the time of
+	// the bid. The end of the session is moved to end unless it is already
+	// later.
+	UpdateBestBid(ctx context.Context, sessionID string, version int, bid domain.TradingBid, end time.Time) error
+	// UpdateLotBestBid is UpdateBestBid for a single lot of the session.
+	UpdateLotBestBid(ctx context.Context, sessionID, lotID string, version int, bid domain.TradingBid, end time.Time) error
+	// SetWinner stores the winning bid of a session determined at close. fence
+	// works as in UpdateStatus.
+	SetWinner(ctx context.Context, sessionID, userID string, price int, fence int64) error
+	// UpdateStatus moves the session from one status to another. A non-zero
+	// fence rejects the change if the session was already changed with a
+	// newer lease token.
+	UpdateStatus(ctx context.Context, sessionID string, from, to domain.SessionStatus, fence int64) error
 }
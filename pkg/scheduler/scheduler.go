@@ -0,0 +1,45 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/log"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/repository/lease"
+	"github.com/Bipolar-Penguin/bff-website/pkg/service"
+)
+
+const leaseName = "scheduler"
+
+// Scheduler opens, closes and awards trading sessions when their dates come. It keeps
+// no state of its own, so after a restart it catches up on the first tick.
+// Only the replica holding the scheduler lease does the work.
+type Scheduler struct {
+	service *service.Service
+	runner  *lease.Runner
+	logger  log.Logger
+}
+
+func NewScheduler(service *service.Service, leases lease.Repository, holder string, interval time.Duration, logger log.Logger) *Scheduler {
+	return &Scheduler{service, lease.NewRunner(leases, leaseName, holder, interval, logger), logger}
+}
+
+// Run works through the session dates until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.runner.Run(ctx, s.tick)
+}
+
+func (s *Scheduler) tick(now time.Time, token int64) {
+	if err := s.service.OpenSessions(now, token); err != nil {
+		s.logger.Log("error", err)
+	}
+
+	if err := s.service.CloseSessions(now, token); err != nil {
+		s.logger.Log("error", err)
+	}
+
+	if err := s.service.AwardSessions(token); err != nil {
+		s.logger.Log("error", err)
+	}
+}
@@ -0,0 +1,115 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+const (
+	highlightOpen  = "<mark>"
+	highlightClose = "</mark>"
+	// snippetContext is the number of words kept around the first match.
+	snippetContext = 12
+)
+
+// token is a word of a text and its position in runes.
+type token struct {
+	word       string
+	start, end int
+}
+
+func tokenize(text []rune) []token {
+	var tokens []token
+
+	start := -1
+
+	for i := 0; i <= len(text); i++ {
+		isWord := i < len(text) && (unicode.IsLetter(text[i]) || unicode.IsDigit(text[i]))
+
+		if isWord && start == -1 {
+			start = i
+		}
+
+		if !isWord && start != -1 {
+			tokens = append(tokens, token{strings.ToLower(string(text[start:i])), start, i})
+			start = -1
+		}
+	}
+
+	return tokens
+}
+
+// Terms returns the distinct stems of the words of a query.
+func Terms(query string) map[string]bool {
+	terms := make(map[string]bool)
+
+	for _, t := range tokenize([]rune(query)) {
+		terms[Stem(t.word)] = true
+	}
+
+	return terms
+}
+
+// Snippet returns an HTML-escaped fragment of text around the first word
+// matching terms, with the matching words wrapped in <mark>. It returns an
+// empty string if no word matches.
+func Snippet(text string, terms map[string]bool) string {
+	runes := []rune(text)
+	tokens := tokenize(runes)
+
+	first := -1
+
+	for i, t := range tokens {
+		if terms[Stem(t.word)] {
+			first = i
+			break
+		}
+	}
+
+	if first == -1 {
+		return ""
+	}
+
+	from := first - snippetContext/2
+	if from < 0 {
+		from = 0
+	}
+
+	to := from + snippetContext
+	if to > len(tokens) {
+		to = len(tokens)
+	}
+
+	var b strings.Builder
+
+	if from > 0 {
+		b.WriteString("… ")
+	}
+
+	// the text around the words is kept at the edges of the whole text only
+	pos := tokens[from].start
+	if from == 0 {
+		pos = 0
+	}
+
+	for _, t := range tokens[from:to] {
+		b.WriteString(html.EscapeString(string(runes[pos:t.start])))
+
+		word := html.EscapeString(string(runes[t.start:t.end]))
+		if terms[Stem(t.word)] {
+			word = highlightOpen + word + highlightClose
+		}
+
+		b.WriteString(word)
+		pos = t.end
+	}
+
+	if to < len(tokens) {
+		b.WriteString(" …")
+	} else {
+		b.WriteString(html.EscapeString(string(runes[pos:])))
+	}
+
+	return b.String()
+}
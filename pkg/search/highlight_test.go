@@ -0,0 +1,69 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTerms(t *testing.T) {
+	got := Terms("Поставка офисной бумаги, 2022")
+	want := map[string]bool{"поставк": true, "офисн": true, "бумаг": true, "2022": true}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Terms() = %v, want %v", got, want)
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		query string
+		want  string
+	}{
+		{
+			name:  "no match",
+			text:  "Ремонт кровли",
+			query: "бумага",
+			want:  "",
+		},
+		{
+			name:  "other word form",
+			text:  "Поставка офисной бумаги.",
+			query: "бумага",
+			want:  "Поставка офисной <mark>бумаги</mark>.",
+		},
+		{
+			name:  "every match is marked",
+			text:  "«Бумага» и ещё бумаги!",
+			query: "бумага",
+			want:  "«<mark>Бумага</mark>» и ещё <mark>бумаги</mark>!",
+		},
+		{
+			name:  "html is escaped",
+			text:  "Картриджи <HP> & Canon",
+			query: "картридж",
+			want:  "<mark>Картриджи</mark> &lt;HP&gt; &amp; Canon",
+		},
+		{
+			name:  "long text is cut around the match",
+			text:  "один два три четыре пять шесть семь восемь девять десять компьютеры одиннадцать двенадцать тринадцать четырнадцать пятнадцать шестнадцать семнадцать",
+			query: "компьютер",
+			want:  "… пять шесть семь восемь девять десять <mark>компьютеры</mark> одиннадцать двенадцать тринадцать четырнадцать пятнадцать …",
+		},
+		{
+			name:  "match near the end keeps the tail",
+			text:  "один два три четыре пять шесть семь восемь девять десять компьютеры (б/у).",
+			query: "компьютер",
+			want:  "… пять шесть семь восемь девять десять <mark>компьютеры</mark> (б/у).",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Snippet(tt.text, Terms(tt.query)); got != tt.want {
+				t.Errorf("Snippet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package search
+
+import "strings"
+
+// Stem reduces a lowercase Russian word to its stem following the Snowball
+// Russian algorithm. Words without Cyrillic vowels are returned unchanged.
+func Stem(word string) string {
+	w := []rune(strings.ReplaceAll(word, "ё", "е"))
+
+	rv := regionAfterVowel(w)
+	if rv == len(w) {
+		return string(w)
+	}
+
+	r2 := regionR2(w)
+
+	// Step 1
+	if stem, ok := removeEnding(w, rv, perfectiveGerund1, perfectiveGerund2); ok {
+		w = stem
+	} else {
+		if stem, ok := removeEnding(w, rv, nil, reflexive); ok {
+			w = stem
+		}
+
+		if stem, ok := removeAdjectival(w, rv); ok {
+			w = stem
+		} else if stem, ok := removeEnding(w, rv, verb1, verb2); ok {
+			w = stem
+		} else if stem, ok := removeEnding(w, rv, nil, noun); ok {
+			w = stem
+		}
+	}
+
+	// Step 2
+	if stem, ok := removeEnding(w, rv, nil, []string{"и"}); ok {
+		w = stem
+	}
+
+	// Step 3
+	if stem, ok := removeEnding(w, r2, nil, derivational); ok {
+		w = stem
+	}
+
+	// Step 4
+	if stem, ok := removeEnding(w, rv, nil, []string{"нн"}); ok {
+		return string(append(stem, 'н'))
+	}
+
+	if stem, ok := removeEnding(w, rv, nil, superlative); ok {
+		w = stem
+		if stem, ok := removeEnding(w, rv, nil, []string{"нн"}); ok {
+			w = append(stem, 'н')
+		}
+
+		return string(w)
+	}
+
+	if stem, ok := removeEnding(w, rv, nil, []string{"ь"}); ok {
+		w = stem
+	}
+
+	return string(w)
+}
+
+var (
+	// endings of group 1 must follow а or я, endings of group 2 need not
+	perfectiveGerund1 = []string{"в", "вши", "вшись"}
+	perfectiveGerund2 = []string{"ив", "ивши", "ившись", "ыв", "ывши", "ывшись"}
+
+	adjective = []string{
+		"ее", "ие", "ые", "ое", "ими", "ыми", "ей", "ий", "ый", "ой", "ем", "им", "ым",
+		"ом", "его", "ого", "ему", "ому", "их", "ых", "ую", "юю", "ая", "яя", "ою", "ею",
+	}
+
+	participle1 = []string{"ем", "нн", "вш", "ющ", "щ"}
+	participle2 = []string{"ивш", "ывш", "ующ"}
+
+	reflexive = []string{"ся", "сь"}
+
+	verb1 = []string{
+		"ла", "на", "ете", "йте", "ли", "й", "л", "ем", "н", "ло", "но", "ет", "ют",
+		"ны", "ть", "ешь", "нно",
+	}
+	verb2 = []string{
+		"ила", "ыла", "ена", "ейте", "уйте", "ите", "или", "ыли", "ей", "уй", "ил",
+		"ыл", "им", "ым", "ен", "ило", "ыло", "ено", "ят", "ует", "уют", "ит", "ыт",
+		"ены", "ить", "ыть", "ишь", "ую", "ю",
+	}
+
+	noun = []string{
+		"а", "ев", "ов", "ие", "ье", "е", "иями", "ями", "ами", "еи", "ии", "и", "ией",
+		"ей", "ой", "ий", "й", "иям", "ям", "ием", "ем", "ам", "ом", "о", "у", "ах",
+		"иях", "ях", "ы", "ь", "ию", "ью", "ю", "ия", "ья", "я",
+	}
+
+	superlative  = []string{"ейш", "ейше"}
+	derivational = []string{"ост", "ость"}
+)
+
+func isVowel(r rune) bool {
+	return strings.ContainsRune("аеиоуыэюя", r)
+}
+
+// regionAfterVowel returns the start of RV, the region after the first vowel.
+func regionAfterVowel(w []rune) int {
+	for i, r := range w {
+		if isVowel(r) {
+			return i + 1
+		}
+	}
+
+	return len(w)
+}
+
+// regionR2 returns the start of R2, the R1 region of R1.
+func regionR2(w []rune) int {
+	r1 := regionR1(w, 0)
+	return regionR1(w, r1)
+}
+
+// regionR1 returns the start of the region after the first non-vowel that
+// follows a vowel, looking from index from.
+func regionR1(w []rune, from int) int {
+	for i := from + 1; i < len(w); i++ {
+		if !isVowel(w[i]) && isVowel(w[i-1]) {
+			return i + 1
+		}
+	}
+
+	return len(w)
+}
+
+// removeEnding removes the longest ending of group1 or group2 lying within the
+// region starting at region. Endings of group1 must be preceded by а or я.
+func removeEnding(w []rune, region int, group1, group2 []string) ([]rune, bool) {
+	best := -1
+
+	for _, ending := range group1 {
+		start, ok := matchEnding(w, region, ending)
+		if !ok || start == 0 || start-1 < region || (w[start-1] != 'а' && w[start-1] != 'я') {
+			continue
+		}
+
+		if best == -1 || start < best {
+			best = start
+		}
+	}
+
+	for _, ending := range group2 {
+		start, ok := matchEnding(w, region, ending)
+		if ok && (best == -1 || start < best) {
+			best = start
+		}
+	}
+
+	if best == -1 {
+		return w, false
+	}
+
+	return w[:best], true
+}
+
+func removeAdjectival(w []rune, rv int) ([]rune, bool) {
+	stem, ok := removeEnding(w, rv, nil, adjective)
+	if !ok {
+		return w, false
+	}
+
+	stem, _ = removeEnding(stem, rv, participle1, participle2)
+
+	return stem, true
+}
+
+func matchEnding(w []rune, region int, ending string) (int, bool) {
+	e := []rune(ending)
+	start := len(w) - len(e)
+
+	if start < region || start < 0 {
+		return 0, false
+	}
+
+	if string(w[start:]) != ending {
+		return 0, false
+	}
+
+	return start, true
+}
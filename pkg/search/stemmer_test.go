@@ -0,0 +1,36 @@
+package search
+
+import "testing"
+
+func TestStem(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"поставка", "поставк"},
+		{"поставки", "поставк"},
+		{"поставку", "поставк"},
+		{"бумага", "бумаг"},
+		{"бумаги", "бумаг"},
+		{"компьютеры", "компьютер"},
+		{"компьютеров", "компьютер"},
+		{"картриджей", "картридж"},
+		{"офисная", "офисн"},
+		{"офисной", "офисн"},
+		{"офисные", "офисн"},
+		{"мебель", "мебел"},
+		{"мебели", "мебел"},
+		{"ремонт", "ремонт"},
+		{"ремонта", "ремонт"},
+		{"строительство", "строительств"},
+		{"ёлки", "елк"},
+		{"pc", "pc"},
+		{"2022", "2022"},
+	}
+
+	for _, tt := range tests {
+		if got := Stem(tt.word); got != tt.want {
+			t.Errorf("Stem(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"math"
+	"time"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+// GetAwards returns the awards of a session, empty until the session is
+// awarded. Only the owner of the session sees who the suppliers are, anybody
+// else sees them by their alias.
+func (s *Service) GetAwards(tradingSessionID, userID string) ([]domain.Award, error) {
+	session, err := s.rep.TradingSession.Find(tradingSessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	awards, err := s.rep.Award.FindMany(tradingSessionID)
+	if err != nil || (userID != "" && userID == session.UserID) {
+		return awards, err
+	}
+
+	for i := range awards {
+		awards[i].Winner.UserID = s.anonymize(tradingSessionID, awards[i].Winner.UserID)
+
+		if awards[i].RunnerUp != nil {
+			runnerUp := *awards[i].RunnerUp
+			runnerUp.UserID = s.anonymize(tradingSessionID, runnerUp.UserID)
+			awards[i].RunnerUp = &runnerUp
+		}
+	}
+
+	return awards, nil
+}
+
+// AwardSessions awards closed sessions that were left unawarded, e.g. by a
+// restart between closing and awarding. fence is the lease token of the caller.
+func (s *Service) AwardSessions(fence int64) error {
+	sessions, err := s.rep.TradingSession.FindByStatus(domain.SessionClosed)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if err := s.awardSession(session.ID, fence); err != nil && !isTransitionError(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// awardSession writes the awards of a closed session, publishes an awarded
+// event for each of them and moves the session to awarded. The status change
+// is fenced and goes first in the same transaction, so a stale lease holder
+// writes no awards. A session nobody bid on is moved to failed instead, so
+// that it is not picked up again.
+func (s *Service) awardSession(sessionID string, fence int64) error {
+	session, err := s.rep.TradingSession.Find(sessionID)
+	if err != nil {
+		return err
+	}
+
+	bids, err := s.rep.TradingBid.FindMany(sessionID)
+	if err != nil {
+		return err
+	}
+
+	awards := makeAwards(session, bids, time.Now())
+
+	status := domain.SessionAwarded
+	if len(awards) == 0 {
+		status = domain.SessionFailed
+	}
+
+	err = s.atomically(func(u *unit) error {
+		if err := s.updateStatus(u, session, status, fence); err != nil {
+			return err
+		}
+
+		// sealed bids are only opened now, the winner becomes the best bid
+		if session.IsSealed() && len(awards) != 0 {
+			award := awards[0]
+
+			err := s.rep.TradingSession.SetWinner(u.ctx, sessionID, award.Winner.UserID, award.Price, fence)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, award := range awards {
+			award, err := s.rep.Award.Save(u.ctx, award)
+			if err != nil {
+				return err
+			}
+
+			err = s.publish(u, domain.Event{
+				GUID:    award.Winner.UserID,
+				Action:  domain.EventActionAwarded,
+				Amount:  award.Price,
+				EventID: award.TradingSessionID,
+				LotID:   award.LotID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.refreshSummary(sessionID)
+
+	return nil
+}
+
+// makeAwards determines the winners of the session. bids are expected in the
+// FindMany order, lowest first and earliest first among equal prices. The
+// winner of a sealed session is taken from the bids alone, so awarding it
+// again after a restart gives the same result.
+func makeAwards(session domain.TradingSession, bids []domain.TradingBid, now time.Time) []domain.Award {
+	var awards []domain.Award
+
+	if session.IsSealed() {
+		_, price, ok := sealedWinner(session, bids)
+		if !ok {
+			return nil
+		}
+
+		award, _ := makeAward(bids, price, session.MaxPrice, now)
+		award.TradingSessionID = session.ID
+
+		return append(awards, award)
+	}
+
+	if len(session.Lots) != 0 && !bundleWins(session) {
+		for _, lot := range session.Lots {
+			if lot.LeaderID == "" {
+				continue
+			}
+
+			award, ok := makeAward(filterBids(bids, lot.ID), lot.CurrentPrice, lot.MaxPrice, now)
+			if !ok {
+				continue
+			}
+
+			award.TradingSessionID = session.ID
+			award.LotID = lot.ID
+			awards = append(awards, award)
+		}
+
+		return awards
+	}
+
+	if session.LeaderID == "" {
+		return nil
+	}
+
+	award, ok := makeAward(filterBids(bids, ""), session.CurrentPrice, session.MaxPrice, now)
+	if !ok {
+		return nil
+	}
+
+	award.TradingSessionID = session.ID
+
+	return append(awards, award)
+}
+
+func makeAward(bids []domain.TradingBid, price, maxPrice int, now time.Time) (domain.Award, bool) {
+	var award domain.Award
+
+	if len(bids) == 0 {
+		return award, false
+	}
+
+	award.Winner = bids[0]
+	award.Price = price
+	award.MaxPrice = maxPrice
+	award.Date = now
+
+	if maxPrice > 0 {
+		savings := float64(maxPrice-price) * 100 / float64(maxPrice)
+		award.SavingsPercent = math.Round(savings*100) / 100
+	}
+
+	tied := 1
+
+	for i := 1; i < len(bids); i++ {
+		bid := bids[i]
+		if bid.UserID == award.Winner.UserID {
+			continue
+		}
+
+		if award.RunnerUp == nil {
+			award.RunnerUp = &bids[i]
+		}
+
+		if bid.Bid == award.Winner.Bid {
+			tied++
+		}
+	}
+
+	if tied > 1 {
+		award.TieBreak = domain.TieBreakEarliest
+		award.TiedBids = tied
+	}
+
+	return award, true
+}
+
+// filterBids returns the bids on the lot, or on the whole session if lotID is empty.
+func filterBids(bids []domain.TradingBid, lotID string) []domain.TradingBid {
+	var res []domain.TradingBid
+
+	for _, bid := range bids {
+		if bid.LotID == lotID {
+			res = append(res, bid)
+		}
+	}
+
+	return res
+}
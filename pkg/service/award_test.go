@@ -0,0 +1,180 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+func TestMakeAwards(t *testing.T) {
+	start := time.Date(2022, 5, 20, 12, 0, 0, 0, time.UTC)
+
+	bid := func(userID, lotID string, price int, minute int) domain.TradingBid {
+		return domain.TradingBid{
+			TradingSessionID: "session",
+			LotID:            lotID,
+			UserID:           userID,
+			Bid:              price,
+			Date:             start.Add(time.Duration(minute) * time.Minute),
+		}
+	}
+
+	// want is the part of an award the cases differ in
+	type want struct {
+		lotID    string
+		winner   string
+		price    int
+		runnerUp string
+		tied     int
+		savings  float64
+	}
+
+	tests := []struct {
+		name    string
+		session domain.TradingSession
+		bids    []domain.TradingBid
+		want    []want
+	}{
+		{
+			name:    "open without bids",
+			session: domain.TradingSession{ID: "session", MaxPrice: 1000},
+		},
+		{
+			name:    "open",
+			session: domain.TradingSession{ID: "session", MaxPrice: 1000, LeaderID: "a", CurrentPrice: 700},
+			bids:    []domain.TradingBid{bid("a", "", 700, 3), bid("b", "", 750, 2), bid("a", "", 800, 1)},
+			want:    []want{{winner: "a", price: 700, runnerUp: "b", savings: 30}},
+		},
+		{
+			name:    "tie goes to the earliest",
+			session: domain.TradingSession{ID: "session", MaxPrice: 900, LeaderID: "a", CurrentPrice: 700},
+			bids:    []domain.TradingBid{bid("a", "", 700, 1), bid("b", "", 700, 2)},
+			want:    []want{{winner: "a", price: 700, runnerUp: "b", tied: 2, savings: 22.22}},
+		},
+		{
+			name:    "sealed without bids",
+			session: domain.TradingSession{ID: "session", MaxPrice: 1000, AuctionType: domain.AuctionSealedFirstPrice},
+		},
+		{
+			name:    "sealed first price",
+			session: domain.TradingSession{ID: "session", MaxPrice: 1000, AuctionType: domain.AuctionSealedFirstPrice},
+			bids:    []domain.TradingBid{bid("a", "", 700, 2), bid("b", "", 800, 1)},
+			want:    []want{{winner: "a", price: 700, runnerUp: "b", savings: 30}},
+		},
+		{
+			name:    "sealed second price",
+			session: domain.TradingSession{ID: "session", MaxPrice: 1000, AuctionType: domain.AuctionSealedSecondPrice},
+			bids:    []domain.TradingBid{bid("a", "", 700, 2), bid("b", "", 800, 1)},
+			want:    []want{{winner: "a", price: 800, runnerUp: "b", savings: 20}},
+		},
+		{
+			name: "lots",
+			session: domain.TradingSession{ID: "session", MaxPrice: 1000, Lots: []domain.Lot{
+				{ID: "l1", MaxPrice: 500, LeaderID: "a", CurrentPrice: 300},
+				{ID: "l2", MaxPrice: 500},
+				{ID: "l3", MaxPrice: 500, LeaderID: "b", CurrentPrice: 400},
+			}},
+			bids: []domain.TradingBid{bid("a", "l1", 300, 1), bid("b", "l3", 400, 2)},
+			want: []want{
+				{lotID: "l1", winner: "a", price: 300, savings: 40},
+				{lotID: "l3", winner: "b", price: 400, savings: 20},
+			},
+		},
+		{
+			name: "bundle beats the lots",
+			session: domain.TradingSession{ID: "session", MaxPrice: 1000, LeaderID: "c", CurrentPrice: 600, Lots: []domain.Lot{
+				{ID: "l1", MaxPrice: 500, LeaderID: "a", CurrentPrice: 300},
+				{ID: "l2", MaxPrice: 500, LeaderID: "b", CurrentPrice: 400},
+			}},
+			bids: []domain.TradingBid{bid("a", "l1", 300, 1), bid("c", "", 600, 3), bid("b", "l2", 400, 2)},
+			want: []want{{winner: "c", price: 600, savings: 40}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			awards := makeAwards(tt.session, tt.bids, start)
+
+			if len(awards) != len(tt.want) {
+				t.Fatalf("makeAwards() made %d awards, want %d", len(awards), len(tt.want))
+			}
+
+			for i, award := range awards {
+				var got want
+
+				got.lotID = award.LotID
+				got.winner = award.Winner.UserID
+				got.price = award.Price
+				got.tied = award.TiedBids
+				got.savings = award.SavingsPercent
+
+				if award.RunnerUp != nil {
+					got.runnerUp = award.RunnerUp.UserID
+				}
+
+				if got != tt.want[i] {
+					t.Errorf("award %d = %+v, want %+v", i, got, tt.want[i])
+				}
+
+				if award.TradingSessionID != "session" || !award.Date.Equal(start) {
+					t.Errorf("award %d is for %q at %v", i, award.TradingSessionID, award.Date)
+				}
+			}
+		})
+	}
+}
+
+func TestGetAwardsAliases(t *testing.T) {
+	session := activeSession()
+	session.UserID = "owner"
+
+	svc, fake := newFakeService(session)
+
+	runnerUp := domain.TradingBid{UserID: "b", Bid: 800}
+	fake.awards.awards = []domain.Award{{
+		TradingSessionID: "session",
+		Winner:           domain.TradingBid{UserID: "a", Bid: 700},
+		RunnerUp:         &runnerUp,
+	}}
+
+	tests := []struct {
+		name     string
+		userID   string
+		winner   string
+		runnerUp string
+	}{
+		{"owner", "owner", "a", "b"},
+		{"anonymous", "", svc.anonymize("session", "a"), svc.anonymize("session", "b")},
+		{"supplier", "a", svc.anonymize("session", "a"), svc.anonymize("session", "b")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			awards, err := svc.GetAwards("session", tt.userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := awards[0].Winner.UserID; got != tt.winner {
+				t.Errorf("winner = %q, want %q", got, tt.winner)
+			}
+
+			if got := awards[0].RunnerUp.UserID; got != tt.runnerUp {
+				t.Errorf("runner-up = %q, want %q", got, tt.runnerUp)
+			}
+		})
+	}
+
+	// the stored awards are left alone
+	if fake.awards.awards[0].RunnerUp.UserID != "b" {
+		t.Errorf("stored runner-up changed to %q", fake.awards.awards[0].RunnerUp.UserID)
+	}
+
+	other, _ := newFakeService()
+	other.aliasKey = []byte("another secret")
+
+	if svc.anonymize("session", "a") == other.anonymize("session", "a") {
+		t.Error("aliases do not depend on the key")
+	}
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+	"github.com/Bipolar-Penguin/bff-website/pkg/repository/trading_session"
+)
+
+// AcceptTradingSession accepts the current clock price of a dutch session for
+// the user and closes the session in the same transaction, so that nobody can
+// accept after the first supplier. Once the accept is stored the bid is
+// returned even if awarding the session fails.
+func (s *Service) AcceptTradingSession(tradingSessionID, userID string) (domain.TradingBid, error) {
+	var newBid domain.TradingBid
+
+	tradingSession, err := s.rep.TradingSession.Find(tradingSessionID)
+	if err != nil {
+		return newBid, err
+	}
+
+	if tradingSession.Type() != domain.AuctionDutch {
+		return newBid, &BidError{Code: BidErrNotSupported}
+	}
+
+	if tradingSession.Status != domain.SessionActive {
+		return newBid, &BidError{Code: BidErrNotActive}
+	}
+
+	now := time.Now()
+
+	if !tradingSession.IsOpen(now) {
+		return newBid, &BidError{Code: BidErrOutsideWindow}
+	}
+
+	newBid.TradingSessionID = tradingSessionID
+	newBid.UserID = userID
+	newBid.Date = now
+	newBid.Bid = tradingSession.ClockPrice(now)
+
+	err = s.atomically(func(u *unit) error {
+		err := s.rep.TradingSession.UpdateBestBid(u.ctx, tradingSessionID, tradingSession.Version, newBid, tradingSession.Date.End)
+		if errors.Is(err, trading_session.ErrVersionConflict) {
+			return &BidError{Code: BidErrOutbid}
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := s.rep.TradingBid.Save(u.ctx, newBid); err != nil {
+			return err
+		}
+
+		err = s.publish(u, domain.Event{
+			GUID:    newBid.UserID,
+			Action:  domain.EventActionAccepted,
+			Amount:  newBid.Bid,
+			EventID: newBid.TradingSessionID,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = s.updateStatus(u, tradingSession, domain.SessionClosed, 0)
+		if isTransitionError(err) {
+			return &BidError{Code: BidErrNotActive}
+		}
+
+		return err
+	})
+	if err != nil {
+		return newBid, err
+	}
+
+	// the accept is committed and the session closed at this point, if
+	// awarding fails the scheduler awards the closed session later
+	s.countBid(newBid)
+
+	err = s.awardSession(tradingSessionID, 0)
+	if err != nil && !isTransitionError(err) {
+		s.logger.Log("session", tradingSessionID, "error", err)
+	}
+
+	return newBid, nil
+}
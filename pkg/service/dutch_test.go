@@ -0,0 +1,43 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+func TestAcceptTradingSessionFirstWins(t *testing.T) {
+	session := activeSession()
+	session.AuctionType = domain.AuctionDutch
+	session.BidStep = domain.BidStep{Type: domain.BidStepFixed, Amount: 100}
+	session.CurrentPrice = 0
+
+	svc, fake := newFakeService(session)
+
+	bid, err := svc.AcceptTradingSession("session", "first")
+	if err != nil {
+		t.Fatalf("first accept: %v", err)
+	}
+
+	_, err = svc.AcceptTradingSession("session", "second")
+
+	var bidErr *BidError
+	if !errors.As(err, &bidErr) || bidErr.Code != BidErrNotActive {
+		t.Fatalf("second accept: %v, want %s", err, BidErrNotActive)
+	}
+
+	stored, _ := fake.sessions.Find("session")
+
+	if stored.LeaderID != "first" || stored.CurrentPrice != bid.Bid {
+		t.Errorf("leader %q at %d, want %q at %d", stored.LeaderID, stored.CurrentPrice, "first", bid.Bid)
+	}
+
+	if stored.Status != domain.SessionAwarded {
+		t.Errorf("status = %s, want %s", stored.Status, domain.SessionAwarded)
+	}
+
+	if len(fake.awards.awards) != 1 || fake.awards.awards[0].Winner.UserID != "first" {
+		t.Errorf("awards = %+v, want one for %q", fake.awards.awards, "first")
+	}
+}
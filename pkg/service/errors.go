@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+var (
+	// ErrBidsSealed is returned when bids of a sealed session are requested before close.
+	ErrBidsSealed = errors.New("bids are sealed until the session closes")
+	// ErrSessionNotFound is returned when there is no session with the given ID.
+	ErrSessionNotFound = errors.New("trading session not found")
+	// ErrSessionChanged is returned when a session was edited since the user read it.
+	ErrSessionChanged = errors.New("trading session was changed by somebody else, reload it")
+	// ErrNotOwner is returned when a user changes a session of somebody else.
+	ErrNotOwner = errors.New("trading session belongs to another user")
+)
+
+const (
+	BidErrInvalidPrice  = "invalid_price"
+	BidErrNotLower      = "price_not_lower"
+	BidErrStepTooSmall  = "step_too_small"
+	BidErrStepTooLarge  = "step_too_large"
+	BidErrBelowFloor    = "below_floor"
+	BidErrAlreadyLeader = "already_leader"
+	BidErrOutbid        = "outbid"
+	BidErrNotActive     = "session_not_active"
+	BidErrOutsideWindow = "outside_window"
+	BidErrSubmitted     = "already_submitted"
+	BidErrNotSupported  = "not_supported"
+	BidErrUnknownLot    = "unknown_lot"
+)
+
+// BidError is returned when a bid does not pass validation. Limit holds the
+// boundary value the bid violated, if any.
+type BidError struct {
+	Code  string
+	Limit int
+}
+
+func (e *BidError) Error() string {
+	switch e.Code {
+	case BidErrInvalidPrice:
+		return "cannot make bid: price must be positive"
+	case BidErrNotLower:
+		return fmt.Sprintf("cannot make bid: price must be lower than %d", e.Limit)
+	case BidErrStepTooSmall:
+		return fmt.Sprintf("cannot make bid: step must be at least %d", e.Limit)
+	case BidErrStepTooLarge:
+		return fmt.Sprintf("cannot make bid: step must be at most %d", e.Limit)
+	case BidErrBelowFloor:
+		return fmt.Sprintf("cannot make bid: price must not be lower than %d", e.Limit)
+	case BidErrAlreadyLeader:
+		return "cannot make bid: you already make a bid"
+	case BidErrNotActive:
+		return "cannot make bid: session is not active"
+	case BidErrOutsideWindow:
+		return "cannot make bid: session is not running at the moment"
+	case BidErrSubmitted:
+		return "cannot make bid: you have already submitted a sealed bid"
+	case BidErrNotSupported:
+		return "cannot make bid: not supported by the auction type"
+	case BidErrUnknownLot:
+		return "cannot make bid: no such lot in the session"
+	case BidErrOutbid:
+		return "cannot make bid: outbid by another supplier, retry"
+	default:
+		return "cannot make bid"
+	}
+}
+
+// TransitionError is returned when a session cannot move to the requested status.
+type TransitionError struct {
+	From domain.SessionStatus
+	To   domain.SessionStatus
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot change session status from %q to %q", e.From, e.To)
+}
+
+// ValidationError is returned when a session does not pass validation.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Message)
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+	"github.com/Bipolar-Penguin/bff-website/pkg/repository"
+	"github.com/Bipolar-Penguin/bff-website/pkg/repository/award"
+	"github.com/Bipolar-Penguin/bff-website/pkg/repository/outbox"
+	"github.com/Bipolar-Penguin/bff-website/pkg/repository/proxy_bid"
+	"github.com/Bipolar-Penguin/bff-website/pkg/repository/session_summary"
+	"github.com/Bipolar-Penguin/bff-website/pkg/repository/trading_bid"
+	"github.com/Bipolar-Penguin/bff-website/pkg/repository/trading_session"
+)
+
+// The fakes keep everything in memory and implement only what the tests
+// reach, the embedded interfaces panic on anything else.
+
+type fakeSessions struct {
+	trading_session.Repository
+
+	mu       sync.Mutex
+	sessions map[string]domain.TradingSession
+
+	// beforeUpdate, if set, runs before UpdateBestBid compares the version
+	beforeUpdate func()
+}
+
+func (f *fakeSessions) Find(sessionID string) (domain.TradingSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.sessions[sessionID], nil
+}
+
+func (f *fakeSessions) UpdateBestBid(ctx context.Context, sessionID string, version int, bid domain.TradingBid, end time.Time) error {
+	if f.beforeUpdate != nil {
+		f.beforeUpdate()
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	session, ok := f.sessions[sessionID]
+	if !ok || session.Version != version || session.Status != domain.SessionActive || !bid.Date.Before(session.Date.End) {
+		return trading_session.ErrVersionConflict
+	}
+
+	session.CurrentPrice = bid.Bid
+	session.LeaderID = bid.UserID
+	session.Version++
+	session.Revision++
+
+	if end.After(session.Date.End) {
+		session.Date.End = end
+	}
+
+	f.sessions[sessionID] = session
+
+	return nil
+}
+
+func (f *fakeSessions) UpdateStatus(ctx context.Context, sessionID string, from, to domain.SessionStatus, fence int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	session, ok := f.sessions[sessionID]
+	if !ok || session.Status != from {
+		return trading_session.ErrStatusConflict
+	}
+
+	session.Status = to
+	session.Revision++
+	f.sessions[sessionID] = session
+
+	return nil
+}
+
+type fakeBids struct {
+	trading_bid.Repository
+
+	mu   sync.Mutex
+	bids []domain.TradingBid
+}
+
+func (f *fakeBids) FindBest(tradingSessionID, lotID string) (domain.TradingBid, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var best domain.TradingBid
+
+	for _, bid := range f.bids {
+		if bid.TradingSessionID != tradingSessionID || bid.LotID != lotID {
+			continue
+		}
+
+		if best.UserID == "" || bid.Bid < best.Bid {
+			best = bid
+		}
+	}
+
+	return best, nil
+}
+
+func (f *fakeBids) FindMany(tradingSessionID string) ([]domain.TradingBid, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var bids []domain.TradingBid
+
+	for _, bid := range f.bids {
+		if bid.TradingSessionID == tradingSessionID {
+			bids = append(bids, bid)
+		}
+	}
+
+	sort.SliceStable(bids, func(i, j int) bool {
+		return bids[i].Bid < bids[j].Bid
+	})
+
+	return bids, nil
+}
+
+func (f *fakeBids) Save(ctx context.Context, bid domain.TradingBid) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.bids = append(f.bids, bid)
+
+	return nil
+}
+
+type fakeAwards struct {
+	award.Repository
+
+	mu     sync.Mutex
+	awards []domain.Award
+}
+
+func (f *fakeAwards) FindMany(tradingSessionID string) ([]domain.Award, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var awards []domain.Award
+
+	for _, award := range f.awards {
+		if award.TradingSessionID == tradingSessionID {
+			awards = append(awards, award)
+		}
+	}
+
+	return awards, nil
+}
+
+func (f *fakeAwards) Save(ctx context.Context, award domain.Award) (domain.Award, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.awards = append(f.awards, award)
+
+	return award, nil
+}
+
+type fakeProxies struct {
+	proxy_bid.Repository
+
+	mu      sync.Mutex
+	proxies []domain.ProxyBid
+}
+
+func (f *fakeProxies) FindActive(tradingSessionID string) ([]domain.ProxyBid, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var proxies []domain.ProxyBid
+
+	for _, proxy := range f.proxies {
+		if proxy.TradingSessionID == tradingSessionID && proxy.Active {
+			proxies = append(proxies, proxy)
+		}
+	}
+
+	sort.Slice(proxies, func(i, j int) bool { return proxies[i].Floor < proxies[j].Floor })
+
+	return proxies, nil
+}
+
+func (f *fakeProxies) Deactivate(ctx context.Context, proxyID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := range f.proxies {
+		if f.proxies[i].ID == proxyID {
+			f.proxies[i].Active = false
+		}
+	}
+
+	return nil
+}
+
+type fakeOutbox struct {
+	outbox.Repository
+
+	mu     sync.Mutex
+	events []domain.Event
+}
+
+func (f *fakeOutbox) Add(ctx context.Context, event domain.Event) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.events = append(f.events, event)
+
+	return nil
+}
+
+type fakeSummaries struct {
+	session_summary.Repository
+
+	// err fails every write
+	err error
+}
+
+func (f *fakeSummaries) SaveSession(session domain.TradingSession, bestPrice int, leader string) error {
+	return f.err
+}
+
+func (f *fakeSummaries) RecordBid(sessionID string, at time.Time) error {
+	return f.err
+}
+
+type fakeBroker struct{}
+
+func (fakeBroker) PublishEvent(event domain.Event) {}
+
+// fakeRepositories are repositories without a mongo client, so transactions
+// run their function as is.
+type fakeRepositories struct {
+	sessions  *fakeSessions
+	bids      *fakeBids
+	awards    *fakeAwards
+	proxies   *fakeProxies
+	outbox    *fakeOutbox
+	summaries *fakeSummaries
+}
+
+func newFakeService(sessions ...domain.TradingSession) (*Service, fakeRepositories) {
+	f := fakeRepositories{
+		sessions:  &fakeSessions{sessions: make(map[string]domain.TradingSession)},
+		bids:      &fakeBids{},
+		awards:    &fakeAwards{},
+		proxies:   &fakeProxies{},
+		outbox:    &fakeOutbox{},
+		summaries: &fakeSummaries{},
+	}
+
+	for _, session := range sessions {
+		f.sessions.sessions[session.ID] = session
+	}
+
+	rep := &repository.Repositories{
+		TradingSession: f.sessions,
+		TradingBid:     f.bids,
+		Award:          f.awards,
+		ProxyBid:       f.proxies,
+		Outbox:         f.outbox,
+		SessionSummary: f.summaries,
+	}
+
+	return NewService(rep, fakeBroker{}, "secret", log.NewNopLogger()), f
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+	"github.com/Bipolar-Penguin/bff-website/pkg/repository/trading_session"
+)
+
+// placeLotBid is placeBid for a single lot of an open session. The step policy
+// of the session is applied to the max price of the lot.
+func (s *Service) placeLotBid(tradingSessionID, lotID, userID string, price int) error {
+	tradingSession, err := s.rep.TradingSession.Find(tradingSessionID)
+	if err != nil {
+		return err
+	}
+
+	if tradingSession.Status != domain.SessionActive {
+		return &BidError{Code: BidErrNotActive}
+	}
+
+	now := time.Now()
+
+	if !tradingSession.IsOpen(now) {
+		return &BidError{Code: BidErrOutsideWindow}
+	}
+
+	lot, ok := tradingSession.Lot(lotID)
+	if !ok {
+		return &BidError{Code: BidErrUnknownLot}
+	}
+
+	if price < 1 {
+		return &BidError{Code: BidErrInvalidPrice}
+	}
+
+	if lot.LeaderID == userID {
+		return &BidError{Code: BidErrAlreadyLeader}
+	}
+
+	err = validateBid(tradingSession.StepPolicy(), lot.MaxPrice, lot.FloorPrice(), lot.BestPrice(), price)
+	if err != nil {
+		return err
+	}
+
+	var newBid domain.TradingBid
+
+	newBid.TradingSessionID = tradingSessionID
+	newBid.LotID = lotID
+	newBid.UserID = userID
+	newBid.Date = now
+	newBid.Bid = price
+
+	end, extended := tradingSession.ExtendedEnd(now)
+
+	err = s.atomically(func(u *unit) error {
+		err := s.rep.TradingSession.UpdateLotBestBid(u.ctx, tradingSessionID, lotID, lot.Version, newBid, end)
+		if errors.Is(err, trading_session.ErrVersionConflict) {
+			return &BidError{Code: BidErrOutbid}
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := s.rep.TradingBid.Save(u.ctx, newBid); err != nil {
+			return err
+		}
+
+		err = s.publish(u, domain.Event{
+			GUID:    newBid.UserID,
+			Action:  domain.EventActionUpdate,
+			Amount:  newBid.Bid,
+			EventID: newBid.TradingSessionID,
+			LotID:   newBid.LotID,
+		})
+		if err != nil || !extended {
+			return err
+		}
+
+		return s.publishExtended(u, tradingSessionID, end)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.countBid(newBid)
+
+	return nil
+}
+
+// bundleWins reports whether the bid on the whole session beats the lot bids.
+// Lot winners are only taken when every lot has a bid and together they are
+// cheaper than the bundle, a tie goes to the bundle. Without a bundle bid the
+// lots win on their own, even if some of them got no bids.
+func bundleWins(tradingSession domain.TradingSession) bool {
+	if tradingSession.LeaderID == "" {
+		return false
+	}
+
+	total := 0
+
+	for _, lot := range tradingSession.Lots {
+		if lot.LeaderID == "" {
+			return true
+		}
+
+		total += lot.CurrentPrice
+	}
+
+	return tradingSession.CurrentPrice <= total
+}
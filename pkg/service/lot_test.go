@@ -0,0 +1,38 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+func TestBundleWins(t *testing.T) {
+	lot := func(leaderID string, price int) domain.Lot {
+		return domain.Lot{LeaderID: leaderID, CurrentPrice: price, MaxPrice: 1000}
+	}
+
+	tests := []struct {
+		name   string
+		leader string
+		price  int
+		lots   []domain.Lot
+		want   bool
+	}{
+		{"no bundle bid", "", 0, []domain.Lot{lot("a", 300), lot("b", 400)}, false},
+		{"no bids at all", "", 0, []domain.Lot{lot("", 0), lot("", 0)}, false},
+		{"lot without bid", "c", 900, []domain.Lot{lot("a", 300), lot("", 0)}, true},
+		{"bundle cheaper", "c", 600, []domain.Lot{lot("a", 300), lot("b", 400)}, true},
+		{"tie goes to the bundle", "c", 700, []domain.Lot{lot("a", 300), lot("b", 400)}, true},
+		{"lots cheaper", "c", 800, []domain.Lot{lot("a", 300), lot("b", 400)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := domain.TradingSession{LeaderID: tt.leader, CurrentPrice: tt.price, Lots: tt.lots}
+
+			if got := bundleWins(session); got != tt.want {
+				t.Errorf("bundleWins() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+// unit is a change in progress: ctx carries its transaction and events are
+// the events published by it so far.
+type unit struct {
+	ctx    context.Context
+	events []domain.Event
+}
+
+// atomically runs fn in a transaction. The events fn publishes are written to
+// the outbox in the same transaction, from where the relay sends them to
+// RabbitMQ, and are handed to the broker once the transaction commits.
+func (s *Service) atomically(fn func(u *unit) error) error {
+	var u unit
+
+	err := s.rep.Transaction(func(ctx context.Context) error {
+		// a retried transaction starts over
+		u = unit{ctx: ctx}
+		return fn(&u)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, event := range u.events {
+		s.broker.PublishEvent(event)
+	}
+
+	return nil
+}
+
+// publish assigns the event an ID and writes it to the outbox of the change.
+func (s *Service) publish(u *unit, event domain.Event) error {
+	event.ID = uuid.NewString()
+
+	if err := s.rep.Outbox.Add(u.ctx, event); err != nil {
+		return err
+	}
+
+	u.events = append(u.events, event)
+
+	return nil
+}
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+	"github.com/Bipolar-Penguin/bff-website/pkg/repository/trading_session"
+)
+
+// maxProxyAttempts is how many times the proxies are settled again when a bid
+// lands while they are being settled.
+const maxProxyAttempts = 3
+
+// RegisterProxyBid sets up a proxy that bids for the user one step at a time
+// while somebody else leads, down to floor.
+func (s *Service) RegisterProxyBid(tradingSessionID, userID string, floor int) (domain.ProxyBid, error) {
+	var proxy domain.ProxyBid
+
+	tradingSession, err := s.rep.TradingSession.Find(tradingSessionID)
+	if err != nil {
+		return proxy, err
+	}
+
+	if tradingSession.Type() != domain.AuctionOpen {
+		return proxy, &BidError{Code: BidErrNotSupported}
+	}
+
+	if !tradingSession.IsOpen(time.Now()) {
+		return proxy, &BidError{Code: BidErrNotActive}
+	}
+
+	if min := tradingSession.FloorPrice(); floor < min {
+		return proxy, &BidError{Code: BidErrBelowFloor, Limit: min}
+	}
+
+	if floor >= tradingSession.BestPrice() {
+		return proxy, &BidError{Code: BidErrNotLower, Limit: tradingSession.BestPrice()}
+	}
+
+	proxy.TradingSessionID = tradingSessionID
+	proxy.UserID = userID
+	proxy.Floor = floor
+	proxy.Active = true
+	proxy.Date = time.Now()
+
+	proxy, err = s.rep.ProxyBid.Save(proxy)
+	if err != nil {
+		return proxy, err
+	}
+
+	// the proxy is registered at this point, the outcome of the competition
+	// is published as events
+	if err := s.runProxies(tradingSessionID, true); err != nil {
+		s.logger.Log("session", tradingSessionID, "error", err)
+	}
+
+	return proxy, nil
+}
+
+// runProxies lets the proxies answer the current leader. The competition is
+// settled in one go and written as the winning bid together with the last bid
+// of every outbid proxy. It extends the session only if extend is set, so that
+// proxies answering a bid do not extend the session again. It is retried if
+// another bid lands meanwhile.
+func (s *Service) runProxies(tradingSessionID string, extend bool) error {
+	for attempt := 0; attempt < maxProxyAttempts; attempt++ {
+		err := s.settleProxies(tradingSessionID, extend)
+		if !errors.Is(err, trading_session.ErrVersionConflict) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) settleProxies(tradingSessionID string, extend bool) error {
+	tradingSession, err := s.rep.TradingSession.Find(tradingSessionID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	if tradingSession.Type() != domain.AuctionOpen || !tradingSession.IsOpen(now) {
+		return nil
+	}
+
+	proxies, err := s.rep.ProxyBid.FindActive(tradingSessionID)
+	if err != nil || len(proxies) == 0 {
+		return err
+	}
+
+	minStep, _ := tradingSession.StepPolicy().Bounds(tradingSession.MaxPrice)
+	outcome := resolveProxies(proxies, tradingSession.LeaderID, tradingSession.BestPrice(), minStep)
+
+	var newBid domain.TradingBid
+
+	newBid.TradingSessionID = tradingSessionID
+	newBid.UserID = outcome.leaderID
+	newBid.Date = now
+	newBid.Bid = outcome.price
+
+	outbid := make([]domain.TradingBid, 0, len(outcome.outbid))
+	for _, bid := range outcome.outbid {
+		bid.TradingSessionID = tradingSessionID
+		bid.Date = now
+		outbid = append(outbid, bid)
+	}
+
+	moved := outcome.price < tradingSession.BestPrice()
+	if !moved && len(outcome.exhausted) == 0 {
+		return nil
+	}
+
+	end, extended := tradingSession.Date.End, false
+	if extend {
+		end, extended = tradingSession.ExtendedEnd(now)
+	}
+
+	err = s.atomically(func(u *unit) error {
+		for _, proxy := range outcome.exhausted {
+			if err := s.exhaustProxy(u, proxy); err != nil {
+				return err
+			}
+		}
+
+		if !moved {
+			return nil
+		}
+
+		err := s.rep.TradingSession.UpdateBestBid(u.ctx, tradingSessionID, tradingSession.Version, newBid, end)
+		if err != nil {
+			return err
+		}
+
+		for _, bid := range outbid {
+			if err := s.rep.TradingBid.Save(u.ctx, bid); err != nil {
+				return err
+			}
+		}
+
+		if err := s.rep.TradingBid.Save(u.ctx, newBid); err != nil {
+			return err
+		}
+
+		for _, action := range []string{domain.EventActionUpdate, domain.EventActionProxyBid} {
+			err := s.publish(u, domain.Event{
+				GUID:    newBid.UserID,
+				Action:  action,
+				Amount:  newBid.Bid,
+				EventID: tradingSessionID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if !extended {
+			return nil
+		}
+
+		return s.publishExtended(u, tradingSessionID, end)
+	})
+	if err != nil || !moved {
+		return err
+	}
+
+	for _, bid := range outbid {
+		s.countBid(bid)
+	}
+
+	s.countBid(newBid)
+
+	return nil
+}
+
+// proxyOutcome is where the proxies get bidding against the leader and each
+// other: the user leading at price and the proxies that cannot go lower.
+// outbid holds the last bid of every exhausted proxy that made one, so that
+// the runner-up and the history do not lose it.
+type proxyOutcome struct {
+	leaderID  string
+	price     int
+	exhausted []domain.ProxyBid
+	outbid    []domain.TradingBid
+}
+
+// resolveProxies works out the outcome of proxies bidding one step at a time
+// against the leader and against each other, as if they took turns, without
+// making every bid: the lowest floor ends up leading one step below the
+// lowest price the others can reach. proxies are expected in the FindActive
+// order.
+func resolveProxies(proxies []domain.ProxyBid, leaderID string, price, step int) proxyOutcome {
+	outcome := proxyOutcome{leaderID: leaderID, price: price}
+	active := append([]domain.ProxyBid(nil), proxies...)
+
+	for {
+		challenger, ok := nextProxy(active, outcome.leaderID)
+		if !ok {
+			return outcome
+		}
+
+		var turns int
+		if step > 0 && outcome.price-step >= challenger.Floor {
+			turns = (outcome.price - challenger.Floor) / step
+		}
+
+		if turns == 0 {
+			outcome.exhausted = append(outcome.exhausted, challenger)
+			active = removeProxy(active, challenger)
+			continue
+		}
+
+		// the leader answers through its own proxy, if it has one
+		defender, defended := proxyOf(active, outcome.leaderID)
+
+		var defenderTurns int
+		if defended {
+			defenderTurns = (outcome.price - defender.Floor) / step
+		}
+
+		steps := battle(turns, defenderTurns)
+		// the loser made the step before the last one, the defender only
+		// bids on even steps
+		lastBid := domain.TradingBid{Bid: outcome.price - (steps-1)*step}
+		outcome.price -= steps * step
+
+		if steps%2 == 1 {
+			outcome.leaderID = challenger.UserID
+			if defended {
+				outcome.exhausted = append(outcome.exhausted, defender)
+				active = removeProxy(active, defender)
+
+				if steps > 1 {
+					lastBid.UserID = defender.UserID
+					outcome.outbid = append(outcome.outbid, lastBid)
+				}
+			}
+		} else {
+			outcome.exhausted = append(outcome.exhausted, challenger)
+			active = removeProxy(active, challenger)
+
+			lastBid.UserID = challenger.UserID
+			outcome.outbid = append(outcome.outbid, lastBid)
+		}
+	}
+}
+
+// battle returns how many steps two bidders taking turns make before one of
+// them cannot go lower. The first can make a steps, the second b, the first
+// bids on odd steps.
+func battle(a, b int) int {
+	// the first odd step above a and the first even step above b
+	oddFail := a + 1 + a%2
+	evenFail := b + 2 - b%2
+
+	if oddFail < evenFail {
+		return oddFail - 1
+	}
+
+	return evenFail - 1
+}
+
+func proxyOf(proxies []domain.ProxyBid, userID string) (domain.ProxyBid, bool) {
+	for _, proxy := range proxies {
+		if proxy.UserID == userID {
+			return proxy, true
+		}
+	}
+
+	return domain.ProxyBid{}, false
+}
+
+func removeProxy(proxies []domain.ProxyBid, proxy domain.ProxyBid) []domain.ProxyBid {
+	var res []domain.ProxyBid
+
+	for _, p := range proxies {
+		if p.ID != proxy.ID {
+			res = append(res, p)
+		}
+	}
+
+	return res
+}
+
+// nextProxy picks the proxy that should answer the current leader. proxies
+// are expected in the FindActive order.
+func nextProxy(proxies []domain.ProxyBid, leaderID string) (domain.ProxyBid, bool) {
+	for _, proxy := range proxies {
+		if proxy.UserID != leaderID {
+			return proxy, true
+		}
+	}
+
+	return domain.ProxyBid{}, false
+}
+
+// exhaustProxy stops a proxy that cannot go lower as a part of the change.
+func (s *Service) exhaustProxy(u *unit, proxy domain.ProxyBid) error {
+	if err := s.rep.ProxyBid.Deactivate(u.ctx, proxy.ID); err != nil {
+		return err
+	}
+
+	return s.publish(u, domain.Event{
+		GUID:    proxy.UserID,
+		Action:  domain.EventActionProxyExhausted,
+		Amount:  proxy.Floor,
+		EventID: proxy.TradingSessionID,
+	})
+}
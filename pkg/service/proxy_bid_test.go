@@ -0,0 +1,174 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+func TestBattle(t *testing.T) {
+	tests := []struct {
+		a, b int
+		want int
+	}{
+		{0, 0, 0},
+		{0, 5, 0},
+		{1, 0, 1},
+		{5, 0, 1},
+		{5, 1, 1},
+		{2, 4, 2},
+		{3, 3, 3},
+		{4, 3, 3},
+		{4, 4, 4},
+	}
+
+	for _, tt := range tests {
+		if got := battle(tt.a, tt.b); got != tt.want {
+			t.Errorf("battle(%d, %d) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestResolveProxies(t *testing.T) {
+	proxy := func(id string, floor int) domain.ProxyBid {
+		return domain.ProxyBid{ID: id, UserID: id, Floor: floor, Active: true}
+	}
+
+	tests := []struct {
+		name      string
+		proxies   []domain.ProxyBid
+		leader    string
+		leaderID  string
+		price     int
+		exhausted []string
+		outbid    []string
+	}{
+		{
+			name:     "no proxies",
+			leader:   "h",
+			leaderID: "h",
+			price:    1000,
+		},
+		{
+			name:     "proxy outbids a bidder",
+			proxies:  []domain.ProxyBid{proxy("p", 500)},
+			leader:   "h",
+			leaderID: "p",
+			price:    900,
+		},
+		{
+			name:      "proxy cannot go lower",
+			proxies:   []domain.ProxyBid{proxy("p", 950)},
+			leader:    "h",
+			leaderID:  "h",
+			price:     1000,
+			exhausted: []string{"p"},
+		},
+		{
+			name:     "leading proxy does not bid against itself",
+			proxies:  []domain.ProxyBid{proxy("p", 500)},
+			leader:   "p",
+			leaderID: "p",
+			price:    1000,
+		},
+		{
+			name:      "lowest floor wins between proxies",
+			proxies:   []domain.ProxyBid{proxy("p1", 500), proxy("p2", 700)},
+			leader:    "h",
+			leaderID:  "p1",
+			price:     700,
+			exhausted: []string{"p2"},
+			outbid:    []string{"p2 800"},
+		},
+		{
+			name:      "leader defends through its proxy",
+			proxies:   []domain.ProxyBid{proxy("h", 600), proxy("p", 800)},
+			leader:    "h",
+			leaderID:  "h",
+			price:     800,
+			exhausted: []string{"p"},
+			outbid:    []string{"p 900"},
+		},
+		{
+			name:      "challenger beats the proxy of the leader",
+			proxies:   []domain.ProxyBid{proxy("p", 500), proxy("h", 900)},
+			leader:    "h",
+			leaderID:  "p",
+			price:     900,
+			exhausted: []string{"h"},
+		},
+		{
+			name:      "proxy of the leader outbid after a bid",
+			proxies:   []domain.ProxyBid{proxy("p", 500), proxy("h", 700)},
+			leader:    "h",
+			leaderID:  "p",
+			price:     700,
+			exhausted: []string{"h"},
+			outbid:    []string{"h 800"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome := resolveProxies(tt.proxies, tt.leader, 1000, 100)
+
+			if outcome.leaderID != tt.leaderID || outcome.price != tt.price {
+				t.Errorf("resolveProxies() leads %q at %d, want %q at %d",
+					outcome.leaderID, outcome.price, tt.leaderID, tt.price)
+			}
+
+			var exhausted []string
+			for _, p := range outcome.exhausted {
+				exhausted = append(exhausted, p.UserID)
+			}
+
+			if !reflect.DeepEqual(exhausted, tt.exhausted) {
+				t.Errorf("exhausted %v, want %v", exhausted, tt.exhausted)
+			}
+
+			var outbid []string
+			for _, bid := range outcome.outbid {
+				outbid = append(outbid, fmt.Sprintf("%s %d", bid.UserID, bid.Bid))
+			}
+
+			if !reflect.DeepEqual(outbid, tt.outbid) {
+				t.Errorf("outbid %v, want %v", outbid, tt.outbid)
+			}
+		})
+	}
+}
+
+func TestRunProxiesKeepsOutbidProxies(t *testing.T) {
+	session := activeSession()
+	session.BidStep = domain.BidStep{Type: domain.BidStepFixed, Amount: 100}
+	session.CurrentPrice = 1000
+	session.LeaderID = "h"
+
+	svc, fake := newFakeService(session)
+	fake.proxies.proxies = []domain.ProxyBid{
+		{ID: "p1", TradingSessionID: "session", UserID: "p1", Floor: 500, Active: true},
+		{ID: "p2", TradingSessionID: "session", UserID: "p2", Floor: 700, Active: true},
+	}
+
+	if err := svc.runProxies("session", false); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, _ := fake.sessions.Find("session")
+	bids, _ := fake.bids.FindMany("session")
+
+	awards := makeAwards(stored, bids, stored.Date.End)
+	if len(awards) != 1 || awards[0].RunnerUp == nil {
+		t.Fatalf("awards = %+v, want one with a runner-up", awards)
+	}
+
+	if got := awards[0].Winner; got.UserID != "p1" || got.Bid != 700 {
+		t.Errorf("winner %q at %d, want p1 at 700", got.UserID, got.Bid)
+	}
+
+	if got := awards[0].RunnerUp; got.UserID != "p2" || got.Bid != 800 {
+		t.Errorf("runner-up %q at %d, want p2 at 800", got.UserID, got.Bid)
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+	"github.com/Bipolar-Penguin/bff-website/pkg/repository/trading_bid"
+)
+
+// placeSealedBid stores the single hidden bid of the user. Sealed bids do not
+// touch the best price of the session and are not published.
+func (s *Service) placeSealedBid(tradingSession domain.TradingSession, userID string, price int) error {
+	now := time.Now()
+
+	if tradingSession.Status != domain.SessionActive {
+		return &BidError{Code: BidErrNotActive}
+	}
+
+	if !tradingSession.IsOpen(now) {
+		return &BidError{Code: BidErrOutsideWindow}
+	}
+
+	if price < 1 {
+		return &BidError{Code: BidErrInvalidPrice}
+	}
+
+	if price >= tradingSession.MaxPrice {
+		return &BidError{Code: BidErrNotLower, Limit: tradingSession.MaxPrice}
+	}
+
+	if floor := tradingSession.FloorPrice(); price < floor {
+		return &BidError{Code: BidErrBelowFloor, Limit: floor}
+	}
+
+	submitted, err := s.rep.TradingBid.Exists(tradingSession.ID, userID)
+	if err != nil {
+		return err
+	}
+
+	if submitted {
+		return &BidError{Code: BidErrSubmitted}
+	}
+
+	var newBid domain.TradingBid
+
+	newBid.TradingSessionID = tradingSession.ID
+	newBid.UserID = userID
+	newBid.Date = now
+	newBid.Bid = price
+	newBid.Sealed = true
+
+	// Exists only saves a write, two concurrent bids are told apart by the
+	// unique index
+	err = s.saveBid(newBid)
+	if errors.Is(err, trading_bid.ErrSealedExists) {
+		return &BidError{Code: BidErrSubmitted}
+	}
+
+	return err
+}
+
+// sealedWinner returns the winning bid and the price the winner is paid. bids
+// are expected lowest first, ties broken by the earliest date. Under the
+// second-price rule the winner gets the runner-up price, or MaxPrice if
+// nobody else bid.
+func sealedWinner(tradingSession domain.TradingSession, bids []domain.TradingBid) (domain.TradingBid, int, bool) {
+	if len(bids) == 0 {
+		return domain.TradingBid{}, 0, false
+	}
+
+	winner := bids[0]
+
+	if tradingSession.Type() != domain.AuctionSealedSecondPrice {
+		return winner, winner.Bid, true
+	}
+
+	if len(bids) == 1 {
+		return winner, tradingSession.MaxPrice, true
+	}
+
+	return winner, bids[1].Bid, true
+}
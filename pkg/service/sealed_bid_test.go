@@ -0,0 +1,42 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+func TestSealedWinner(t *testing.T) {
+	bids := []domain.TradingBid{
+		{UserID: "a", Bid: 700},
+		{UserID: "b", Bid: 800},
+		{UserID: "c", Bid: 900},
+	}
+
+	tests := []struct {
+		name        string
+		auctionType domain.AuctionType
+		bids        []domain.TradingBid
+		winner      string
+		price       int
+		ok          bool
+	}{
+		{"no bids", domain.AuctionSealedFirstPrice, nil, "", 0, false},
+		{"first price", domain.AuctionSealedFirstPrice, bids, "a", 700, true},
+		{"second price", domain.AuctionSealedSecondPrice, bids, "a", 800, true},
+		{"second price single bid", domain.AuctionSealedSecondPrice, bids[:1], "a", 1000, true},
+		{"first price single bid", domain.AuctionSealedFirstPrice, bids[:1], "a", 700, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := domain.TradingSession{AuctionType: tt.auctionType, MaxPrice: 1000}
+
+			winner, price, ok := sealedWinner(session, tt.bids)
+			if winner.UserID != tt.winner || price != tt.price || ok != tt.ok {
+				t.Errorf("sealedWinner() = %q, %d, %v, want %q, %d, %v",
+					winner.UserID, price, ok, tt.winner, tt.price, tt.ok)
+			}
+		})
+	}
+}
@@ -2,12 +2,16 @@ package service
 
 import (
 	"errors"
-	"fmt"
+	"html"
 	"time"
 
 	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+	"github.com/Bipolar-Penguin/bff-website/pkg/pubsub"
 	"github.com/Bipolar-Penguin/bff-website/pkg/repository"
-	"github.com/Bipolar-Penguin/bff-website/pkg/transport/amqp"
+	"github.com/Bipolar-Penguin/bff-website/pkg/repository/session_summary"
+	"github.com/Bipolar-Penguin/bff-website/pkg/repository/trading_session"
+	"github.com/Bipolar-Penguin/bff-website/pkg/search"
+	"github.com/go-kit/log"
 	"github.com/golang-jwt/jwt"
 )
 
@@ -17,6 +21,22 @@ const (
 	signingKey = "foobar"
 )
 
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// publicStatuses are listed when no status is asked for.
+var publicStatuses = []domain.SessionStatus{
+	domain.SessionPublished,
+	domain.SessionActive,
+	domain.SessionSuspended,
+	domain.SessionClosed,
+	domain.SessionAwarded,
+	domain.SessionFailed,
+	domain.SessionCancelled,
+}
+
 type tokenClaims struct {
 	jwt.StandardClaims
 	UserID string `json:"user_id"`
@@ -24,101 +44,387 @@ type tokenClaims struct {
 
 type Service struct {
 	rep    *repository.Repositories
-	broker *amqp.RabbitBroker
+	broker pubsub.Publisher
+	// aliasKey keys the aliases suppliers are shown by to the public
+	aliasKey []byte
+	// logger reports failures of the work done after a change was committed
+	logger log.Logger
 }
 
-func NewService(rep *repository.Repositories, broker *amqp.RabbitBroker) *Service {
-	return &Service{rep, broker}
+func NewService(rep *repository.Repositories, broker pubsub.Publisher, aliasSecret string, logger log.Logger) *Service {
+	return &Service{rep, broker, []byte(aliasSecret), logger}
 }
 
 // Trading bids features
-func (s *Service) GetTradingBids(tradingSessionID string) ([]domain.TradingBid, error) {
-	return s.rep.TradingBid.FindMany(tradingSessionID)
+
+// GetTradingBids returns a page of the bid history of the session, the latest first.
+func (s *Service) GetTradingBids(tradingSessionID string, offset, limit int) ([]domain.TradingBid, error) {
+	tradingSession, err := s.rep.TradingSession.Find(tradingSessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tradingSession.IsSealed() && !isFinished(tradingSession.Status) {
+		return nil, ErrBidsSealed
+	}
+
+	if limit <= 0 || limit > maxPageSize {
+		limit = defaultPageSize
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	bids, err := s.rep.TradingBid.FindHistory(tradingSessionID, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// suppliers are shown by their alias, as the leader of the summary
+	for i := range bids {
+		bids[i].UserID = s.anonymize(tradingSessionID, bids[i].UserID)
+	}
+
+	return bids, nil
 }
 
-func (s *Service) MakeTradingBid(tradingSessionID, userID string) error {
+// MakeTradingBid places a bid of the given price on the lot or, if lotID is
+// empty, on the whole session and lets proxies of other suppliers respond to it.
+func (s *Service) MakeTradingBid(tradingSessionID, lotID, userID string, price int) error {
 	tradingSession, err := s.rep.TradingSession.Find(tradingSessionID)
 	if err != nil {
 		return err
 	}
-	bids, err := s.rep.TradingBid.FindMany(tradingSessionID)
+
+	if lotID != "" && tradingSession.Type() != domain.AuctionOpen {
+		return &BidError{Code: BidErrNotSupported}
+	}
+
+	if tradingSession.IsSealed() {
+		return s.placeSealedBid(tradingSession, userID, price)
+	}
+
+	if tradingSession.Type() == domain.AuctionDutch {
+		return &BidError{Code: BidErrNotSupported}
+	}
+
+	if lotID != "" {
+		return s.placeLotBid(tradingSessionID, lotID, userID, price)
+	}
+
+	if err := s.placeBid(tradingSessionID, userID, price); err != nil {
+		return err
+	}
+
+	// the bid is accepted at this point, the proxies answering it are not the
+	// bidder's concern
+	if err := s.runProxies(tradingSessionID, false); err != nil {
+		s.logger.Log("session", tradingSessionID, "error", err)
+	}
+
+	return nil
+}
+
+// placeBid checks the price against the current best bid, the session step
+// policy and the floor price. The best bid is updated with a version check,
+// so of two concurrent bids only one wins and the other gets BidErrOutbid.
+func (s *Service) placeBid(tradingSessionID, userID string, price int) error {
+	tradingSession, err := s.rep.TradingSession.Find(tradingSessionID)
 	if err != nil {
 		return err
 	}
 
-	if len(bids) == 0 {
-		var newBid domain.TradingBid
+	if tradingSession.Status != domain.SessionActive {
+		return &BidError{Code: BidErrNotActive}
+	}
 
-		newBid.TradingSessionID = tradingSessionID
-		newBid.UserID = userID
-		newBid.Date = time.Now()
-		newBid.Bid = tradingSession.MaxPrice - int(float64(tradingSession.MaxPrice)*0.01)
-		fmt.Println(newBid)
+	now := time.Now()
 
-		if newBid.Bid < 1 {
-			return errors.New("cannot make bid: min price reached")
+	if !tradingSession.IsOpen(now) {
+		return &BidError{Code: BidErrOutsideWindow}
+	}
+
+	if price < 1 {
+		return &BidError{Code: BidErrInvalidPrice}
+	}
+
+	currentPrice, leaderID := tradingSession.BestPrice(), tradingSession.LeaderID
+
+	if tradingSession.Version == 0 {
+		// bids made before the best bid was kept on the session are only
+		// found in the bids collection
+		best, err := s.rep.TradingBid.FindBest(tradingSessionID, "")
+		if err != nil {
+			return err
+		}
+
+		if best.UserID != "" {
+			currentPrice, leaderID = best.Bid, best.UserID
+		}
+	}
+
+	if leaderID == userID {
+		return &BidError{Code: BidErrAlreadyLeader}
+	}
+
+	err = validateBid(
+		tradingSession.StepPolicy(),
+		tradingSession.MaxPrice,
+		tradingSession.FloorPrice(),
+		currentPrice,
+		price,
+	)
+	if err != nil {
+		return err
+	}
+
+	var newBid domain.TradingBid
+
+	newBid.TradingSessionID = tradingSessionID
+	newBid.UserID = userID
+	newBid.Date = now
+	newBid.Bid = price
+
+	end, extended := tradingSession.ExtendedEnd(now)
+
+	err = s.atomically(func(u *unit) error {
+		err := s.rep.TradingSession.UpdateBestBid(u.ctx, tradingSessionID, tradingSession.Version, newBid, end)
+		if errors.Is(err, trading_session.ErrVersionConflict) {
+			return &BidError{Code: BidErrOutbid}
 		}
 
-		if err := s.rep.TradingBid.Save(newBid); err != nil {
+		if err != nil {
 			return err
 		}
 
-		s.broker.PublishEvent(domain.Event{
+		if err := s.rep.TradingBid.Save(u.ctx, newBid); err != nil {
+			return err
+		}
+
+		err = s.publish(u, domain.Event{
 			GUID:    newBid.UserID,
-			Action:  "update",
+			Action:  domain.EventActionUpdate,
 			Amount:  newBid.Bid,
 			EventID: newBid.TradingSessionID,
 		})
-		return nil
+		if err != nil || !extended {
+			return err
+		}
+
+		return s.publishExtended(u, tradingSessionID, end)
+	})
+	if err != nil {
+		return err
 	}
 
-	if bids[0].UserID == userID {
-		return errors.New("cannot make bid: you already make a bid")
+	s.countBid(newBid)
+
+	return nil
+}
+
+// validateBid checks price against the current price, the floor and the step
+// policy applied to maxPrice.
+func validateBid(policy domain.BidStep, maxPrice, floor, currentPrice, price int) error {
+	if price >= currentPrice {
+		return &BidError{Code: BidErrNotLower, Limit: currentPrice}
 	}
 
-	newBid := bids[0]
+	if price < floor {
+		return &BidError{Code: BidErrBelowFloor, Limit: floor}
+	}
 
-	newBid.TradingSessionID = tradingSessionID
-	newBid.UserID = userID
-	newBid.Date = time.Now()
-	newBid.Bid = newBid.Bid - int(float64(tradingSession.MaxPrice)*0.01)
-	fmt.Println(newBid)
+	minStep, maxStep := policy.Bounds(maxPrice)
+	step := currentPrice - price
 
-	if newBid.Bid < 1 {
-		return errors.New("cannot make bid: min price reached")
+	if step < minStep {
+		return &BidError{Code: BidErrStepTooSmall, Limit: minStep}
 	}
 
-	if err := s.rep.TradingBid.Save(newBid); err != nil {
-		return err
+	if maxStep > 0 && step > maxStep {
+		return &BidError{Code: BidErrStepTooLarge, Limit: maxStep}
 	}
 
-	s.broker.PublishEvent(domain.Event{
-		GUID:    newBid.UserID,
-		Action:  "update",
-		Amount:  newBid.Bid,
-		EventID: newBid.TradingSessionID,
-	})
 	return nil
 }
 
 // Trading session features
-func (s *Service) GetSessions() ([]domain.TradingSession, error) {
-	return s.rep.TradingSession.FindAll()
+
+// GetSessions returns a page of sessions and the cursor of the next one.
+// Drafts are listed only when the user asks for their own drafts.
+func (s *Service) GetSessions(userID string, filter session_summary.Filter) ([]domain.SessionView, string, error) {
+	if filter.Limit <= 0 || filter.Limit > maxPageSize {
+		filter.Limit = defaultPageSize
+	}
+
+	if len(filter.Statuses) == 0 {
+		filter.Statuses = publicStatuses
+	}
+
+	for _, status := range filter.Statuses {
+		if status == domain.SessionDraft && (userID == "" || filter.UserID != userID) {
+			return nil, "", ErrNotOwner
+		}
+	}
+
+	return s.rep.SessionSummary.FindPage(filter)
 }
 
-func (s *Service) ExtendSession(sessionID string) error {
+// SearchSessions finds public sessions by a text query and highlights the
+// matched words in their titles and descriptions.
+func (s *Service) SearchSessions(query string, limit int) ([]domain.SessionHit, error) {
+	if limit <= 0 || limit > maxPageSize {
+		limit = defaultPageSize
+	}
+
+	hits, err := s.rep.TradingSession.Search(query, publicStatuses, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := search.Terms(query)
+
+	for i := range hits {
+		hits[i].Session.BidStep = hits[i].Session.StepPolicy()
+		hits[i].TitleSnippet = search.Snippet(hits[i].Session.Title, terms)
+		hits[i].DescriptionSnippet = search.Snippet(hits[i].Session.Description, terms)
+
+		if hits[i].TitleSnippet == "" {
+			hits[i].TitleSnippet = html.EscapeString(hits[i].Session.Title)
+		}
+	}
+
+	return hits, nil
+}
+
+// publishExtended publishes the new end of a session the anti-sniping rule
+// extended. The end is moved by the bid itself, so that a session cannot be
+// closed between a bid and its extension.
+func (s *Service) publishExtended(u *unit, sessionID string, end time.Time) error {
+	return s.publish(u, domain.Event{
+		Action:  domain.EventActionExtended,
+		EventID: sessionID,
+		End:     &end,
+	})
+}
+
+// TransitionSession moves the session to the given status if the lifecycle
+// allows it and publishes a status event.
+func (s *Service) TransitionSession(sessionID string, to domain.SessionStatus) error {
+	return s.transitionSession(sessionID, to, 0)
+}
+
+func (s *Service) transitionSession(sessionID string, to domain.SessionStatus, fence int64) error {
 	session, err := s.rep.TradingSession.Find(sessionID)
 	if err != nil {
 		return err
 	}
 
-	session.Date.End.Add(time.Duration(5 * time.Minute))
+	err = s.atomically(func(u *unit) error {
+		return s.updateStatus(u, session, to, fence)
+	})
+	if err != nil {
+		return err
+	}
 
-	err = s.rep.TradingSession.Save(session)
+	s.refreshSummary(sessionID)
 
 	return nil
 }
 
+// updateStatus moves the session to the given status as a part of the change
+// and publishes a status event. A session changed since it was read or by a
+// newer lease holder gets a TransitionError.
+func (s *Service) updateStatus(u *unit, session domain.TradingSession, to domain.SessionStatus, fence int64) error {
+	if !session.Status.CanTransitionTo(to) {
+		return &TransitionError{From: session.Status, To: to}
+	}
+
+	err := s.rep.TradingSession.UpdateStatus(u.ctx, session.ID, session.Status, to, fence)
+	if errors.Is(err, trading_session.ErrStatusConflict) {
+		return &TransitionError{From: session.Status, To: to}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return s.publish(u, domain.Event{
+		Action:  domain.EventActionStatus,
+		EventID: session.ID,
+		Status:  string(to),
+	})
+}
+
+// OpenSessions activates every published session whose start has come. fence
+// is the lease token of the caller.
+func (s *Service) OpenSessions(now time.Time, fence int64) error {
+	sessions, err := s.rep.TradingSession.FindStarting(now)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		err := s.transitionSession(session.ID, domain.SessionActive, fence)
+		if err != nil && !isTransitionError(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CloseSessions closes every active session whose end has passed and
+// awards it. fence is the lease token of the caller.
+func (s *Service) CloseSessions(now time.Time, fence int64) error {
+	sessions, err := s.rep.TradingSession.FindEnding(now)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if err := s.CloseSession(session.ID, now, fence); err != nil && !isTransitionError(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CloseSession closes the session if its end has passed and awards it, if
+// there is a winner.
+func (s *Service) CloseSession(sessionID string, now time.Time, fence int64) error {
+	session, err := s.rep.TradingSession.Find(sessionID)
+	if err != nil {
+		return err
+	}
+
+	// the session may have been extended since it was selected for closing
+	if now.Before(session.Date.End) {
+		return nil
+	}
+
+	return s.closeSession(session, fence)
+}
+
+// closeSession closes the session regardless of its end date.
+func (s *Service) closeSession(session domain.TradingSession, fence int64) error {
+	if err := s.transitionSession(session.ID, domain.SessionClosed, fence); err != nil {
+		return err
+	}
+
+	return s.awardSession(session.ID, fence)
+}
+
+func isFinished(status domain.SessionStatus) bool {
+	return status == domain.SessionClosed || status == domain.SessionAwarded || status == domain.SessionFailed
+}
+
+func isTransitionError(err error) bool {
+	var transitionErr *TransitionError
+	return errors.As(err, &transitionErr)
+}
+
 // User features
 func (s *Service) SaveUser(user domain.User) (domain.User, error) {
 	return s.rep.User.Save(user)
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+func activeSession() domain.TradingSession {
+	session := domain.TradingSession{
+		ID:       "session",
+		Status:   domain.SessionActive,
+		MaxPrice: 1000000,
+		BidStep:  domain.BidStep{Type: domain.BidStepRange, Min: 1, Max: 100000},
+		Version:  1,
+	}
+
+	session.CurrentPrice = session.MaxPrice
+	session.Date.Start = time.Now().Add(-time.Hour)
+	session.Date.End = time.Now().Add(time.Hour)
+
+	return session
+}
+
+func TestMakeTradingBidConcurrent(t *testing.T) {
+	const (
+		bidders = 16
+		rounds  = 5
+	)
+
+	svc, fake := newFakeService(activeSession())
+
+	for round := 0; round < rounds; round++ {
+		before, _ := fake.sessions.Find("session")
+
+		// every bidder reads the session before any of them updates it, so
+		// that all of them compete for the same version
+		var arrived sync.WaitGroup
+		arrived.Add(bidders)
+
+		fake.sessions.beforeUpdate = func() {
+			arrived.Done()
+			arrived.Wait()
+		}
+
+		errs := make([]error, bidders)
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < bidders; i++ {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				userID := fmt.Sprintf("user-%d-%d", round, i)
+				errs[i] = svc.MakeTradingBid("session", "", userID, before.CurrentPrice-(i+1))
+			}(i)
+		}
+
+		wg.Wait()
+
+		winner := -1
+
+		for i, err := range errs {
+			var bidErr *BidError
+
+			switch {
+			case err == nil:
+				if winner != -1 {
+					t.Fatalf("round %d: bidders %d and %d both won version %d", round, winner, i, before.Version)
+				}
+
+				winner = i
+			case errors.As(err, &bidErr) && bidErr.Code == BidErrOutbid:
+			default:
+				t.Fatalf("round %d: bidder %d got %v, want nil or %s", round, i, err, BidErrOutbid)
+			}
+		}
+
+		if winner == -1 {
+			t.Fatalf("round %d: no bid won version %d", round, before.Version)
+		}
+
+		after, _ := fake.sessions.Find("session")
+
+		if after.Version != before.Version+1 {
+			t.Errorf("round %d: version = %d, want %d", round, after.Version, before.Version+1)
+		}
+
+		if want := before.CurrentPrice - (winner + 1); after.CurrentPrice != want {
+			t.Errorf("round %d: current price = %d, want %d", round, after.CurrentPrice, want)
+		}
+
+		if want := fmt.Sprintf("user-%d-%d", round, winner); after.LeaderID != want {
+			t.Errorf("round %d: leader = %q, want %q", round, after.LeaderID, want)
+		}
+	}
+
+	fake.sessions.beforeUpdate = nil
+
+	if len(fake.bids.bids) != rounds {
+		t.Errorf("saved %d bids, want %d", len(fake.bids.bids), rounds)
+	}
+
+	if len(fake.outbox.events) != rounds {
+		t.Errorf("published %d events, want %d", len(fake.outbox.events), rounds)
+	}
+}
+
+func TestMakeTradingBidSummaryFailure(t *testing.T) {
+	svc, fake := newFakeService(activeSession())
+	fake.summaries.err = errors.New("read model unavailable")
+
+	if err := svc.MakeTradingBid("session", "", "user", 999000); err != nil {
+		t.Fatalf("MakeTradingBid() = %v, want nil for a committed bid", err)
+	}
+
+	if stored, _ := fake.sessions.Find("session"); stored.LeaderID != "user" {
+		t.Errorf("leader = %q, want %q", stored.LeaderID, "user")
+	}
+}
+
+func TestValidateBid(t *testing.T) {
+	fixed := domain.BidStep{Type: domain.BidStepFixed, Amount: 100}
+	ranged := domain.BidStep{Type: domain.BidStepRange, Min: 50, Max: 500}
+	percent := domain.BidStep{Type: domain.BidStepPercent, Percent: 1}
+
+	tests := []struct {
+		name     string
+		policy   domain.BidStep
+		maxPrice int
+		floor    int
+		current  int
+		price    int
+		code     string
+		limit    int
+	}{
+		{"fixed step", fixed, 10000, 5000, 8000, 7900, "", 0},
+		{"same price", fixed, 10000, 5000, 8000, 8000, BidErrNotLower, 8000},
+		{"higher price", fixed, 10000, 5000, 8000, 8100, BidErrNotLower, 8000},
+		{"below the floor", fixed, 10000, 5000, 8000, 4900, BidErrBelowFloor, 5000},
+		{"down to the floor", ranged, 10000, 5000, 5100, 5000, "", 0},
+		{"fixed step too small", fixed, 10000, 5000, 8000, 7950, BidErrStepTooSmall, 100},
+		{"fixed step too large", fixed, 10000, 5000, 8000, 7800, BidErrStepTooLarge, 100},
+		{"range lower bound", ranged, 10000, 5000, 8000, 7950, "", 0},
+		{"range upper bound", ranged, 10000, 5000, 8000, 7500, "", 0},
+		{"range too small", ranged, 10000, 5000, 8000, 7990, BidErrStepTooSmall, 50},
+		{"range too large", ranged, 10000, 5000, 8000, 7400, BidErrStepTooLarge, 500},
+		{"percent of max price", percent, 10000, 5000, 8000, 7900, "", 0},
+		{"percent too large", percent, 10000, 5000, 8000, 7800, BidErrStepTooLarge, 100},
+		{"percent below a kopeck", percent, 50, 1, 50, 49, "", 0},
+		{"percent below a kopeck too large", percent, 50, 1, 50, 10, BidErrStepTooLarge, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBid(tt.policy, tt.maxPrice, tt.floor, tt.current, tt.price)
+
+			if tt.code == "" {
+				if err != nil {
+					t.Fatalf("validateBid() = %v, want nil", err)
+				}
+
+				return
+			}
+
+			var bidErr *BidError
+			if !errors.As(err, &bidErr) || bidErr.Code != tt.code || bidErr.Limit != tt.limit {
+				t.Fatalf("validateBid() = %v, want %s with limit %d", err, tt.code, tt.limit)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"errors"
+	"net/url"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+	"github.com/Bipolar-Penguin/bff-website/pkg/repository/trading_session"
+)
+
+const (
+	maxTitleLength       = 300
+	maxDescriptionLength = 10000
+	maxImages            = 20
+)
+
+// CreateSession stores a new draft session owned by the user.
+func (s *Service) CreateSession(userID string, session domain.TradingSession) (domain.TradingSession, error) {
+	session.UserID = userID
+	session.Status = domain.SessionDraft
+	resetProgress(&session)
+
+	if err := validateSession(session); err != nil {
+		return session, err
+	}
+
+	session, err := s.rep.TradingSession.Create(session)
+	if err != nil {
+		return session, err
+	}
+
+	s.syncSummary(session)
+
+	return session, nil
+}
+
+// UpdateSession replaces the editable fields of a draft session of the user.
+// session.Version must be the version the user has seen.
+func (s *Service) UpdateSession(userID string, session domain.TradingSession) (domain.TradingSession, error) {
+	stored, err := s.findOwnSession(userID, session.ID)
+	if err != nil {
+		return session, err
+	}
+
+	if stored.Status != domain.SessionDraft {
+		return session, &ValidationError{Field: "status", Message: "only draft sessions can be edited"}
+	}
+
+	version := session.Version
+
+	resetProgress(&session)
+	// the fields owned by the server are kept from the stored session
+	session.UserID = stored.UserID
+	session.Status = stored.Status
+	session.CreatedAt = stored.CreatedAt
+	session.Fence = stored.Fence
+	session.Revision = stored.Revision
+	session.Version = version
+
+	if err := validateSession(session); err != nil {
+		return session, err
+	}
+
+	session, err = s.rep.TradingSession.Update(session)
+	if errors.Is(err, trading_session.ErrNotFound) {
+		return session, ErrSessionNotFound
+	}
+
+	if errors.Is(err, trading_session.ErrVersionConflict) {
+		return session, ErrSessionChanged
+	}
+
+	if err != nil {
+		return session, err
+	}
+
+	s.syncSummary(session)
+
+	return session, nil
+}
+
+// PublishSession makes a draft session of the user visible to suppliers.
+func (s *Service) PublishSession(userID, sessionID string) error {
+	session, err := s.findOwnSession(userID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if err := validateSession(session); err != nil {
+		return err
+	}
+
+	if !session.Date.Start.After(time.Now()) {
+		return &ValidationError{Field: "date.start", Message: "must be in the future"}
+	}
+
+	return s.TransitionSession(sessionID, domain.SessionPublished)
+}
+
+// CancelSession cancels a session of the user.
+func (s *Service) CancelSession(userID, sessionID string) error {
+	if _, err := s.findOwnSession(userID, sessionID); err != nil {
+		return err
+	}
+
+	return s.TransitionSession(sessionID, domain.SessionCancelled)
+}
+
+func (s *Service) findOwnSession(userID, sessionID string) (domain.TradingSession, error) {
+	session, err := s.rep.TradingSession.Find(sessionID)
+	if err != nil {
+		return session, err
+	}
+
+	if session.ID == "" {
+		return session, ErrSessionNotFound
+	}
+
+	if session.UserID != userID {
+		return session, ErrNotOwner
+	}
+
+	return session, nil
+}
+
+// resetProgress clears the bidding state a client must not set, and gives
+// the lots new IDs. Lot IDs are never taken from the client, a draft has no
+// bids referring to them yet.
+func resetProgress(session *domain.TradingSession) {
+	session.CurrentPrice = 0
+	session.LeaderID = ""
+	session.Version = 0
+	session.Fence = 0
+
+	for i := range session.Lots {
+		session.Lots[i].CurrentPrice = 0
+		session.Lots[i].LeaderID = ""
+		session.Lots[i].Version = 0
+		session.Lots[i].ID = uuid.NewString()
+	}
+}
+
+func validateSession(session domain.TradingSession) error { // nolint:gocyclo
+	if session.Title == "" {
+		return &ValidationError{Field: "title", Message: "must not be empty"}
+	}
+
+	if utf8.RuneCountInString(session.Title) > maxTitleLength {
+		return &ValidationError{Field: "title", Message: "is too long"}
+	}
+
+	if utf8.RuneCountInString(session.Description) > maxDescriptionLength {
+		return &ValidationError{Field: "description", Message: "is too long"}
+	}
+
+	switch session.Type() {
+	case domain.AuctionOpen, domain.AuctionSealedFirstPrice, domain.AuctionSealedSecondPrice, domain.AuctionDutch:
+	default:
+		return &ValidationError{Field: "auction_type", Message: "is unknown"}
+	}
+
+	if session.MaxPrice < 1 {
+		return &ValidationError{Field: "max_price", Message: "must be positive"}
+	}
+
+	if session.MinPrice < 0 || session.MinPrice >= session.MaxPrice {
+		return &ValidationError{Field: "min_price", Message: "must be between 0 and max_price"}
+	}
+
+	if err := validateBidStep(session.BidStep); err != nil {
+		return err
+	}
+
+	if session.Extension.Window < 0 || session.Extension.Extend < 0 {
+		return &ValidationError{Field: "extension", Message: "must not be negative"}
+	}
+
+	if session.Date.Start.IsZero() || !session.Date.End.After(session.Date.Start) {
+		return &ValidationError{Field: "date", Message: "end must be after start"}
+	}
+
+	if len(session.ImageURLs) > maxImages {
+		return &ValidationError{Field: "image_urls", Message: "too many images"}
+	}
+
+	for _, imageURL := range session.ImageURLs {
+		u, err := url.ParseRequestURI(imageURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return &ValidationError{Field: "image_urls", Message: "must be http(s) URLs"}
+		}
+	}
+
+	if len(session.Lots) != 0 && session.Type() != domain.AuctionOpen {
+		return &ValidationError{Field: "lots", Message: "are supported by open auctions only"}
+	}
+
+	total := 0
+
+	for _, lot := range session.Lots {
+		total += lot.MaxPrice
+
+		if lot.Title == "" {
+			return &ValidationError{Field: "lots.title", Message: "must not be empty"}
+		}
+
+		if lot.MaxPrice < 1 || lot.Quantity <= 0 {
+			return &ValidationError{Field: "lots", Message: "max_price and quantity must be positive"}
+		}
+
+		if lot.MinPrice < 0 || lot.MinPrice >= lot.MaxPrice {
+			return &ValidationError{Field: "lots.min_price", Message: "must be between 0 and max_price"}
+		}
+	}
+
+	// a bid on the whole session replaces bids on every lot
+	if len(session.Lots) != 0 && session.MaxPrice > total {
+		return &ValidationError{Field: "max_price", Message: "must not exceed the sum of the lot max prices"}
+	}
+
+	return nil
+}
+
+func validateBidStep(step domain.BidStep) error {
+	switch step.Type {
+	case "":
+		return nil
+	case domain.BidStepPercent:
+		if step.Percent <= 0 || step.Percent >= 100 {
+			return &ValidationError{Field: "bid_step.percent", Message: "must be between 0 and 100"}
+		}
+	case domain.BidStepFixed:
+		if step.Amount < 1 {
+			return &ValidationError{Field: "bid_step.amount", Message: "must be positive"}
+		}
+	case domain.BidStepRange:
+		if step.Min < 1 || step.Max < step.Min {
+			return &ValidationError{Field: "bid_step", Message: "min must be positive and not above max"}
+		}
+	default:
+		return &ValidationError{Field: "bid_step.type", Message: "is unknown"}
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+// GetSession returns a public session with its live summary.
+func (s *Service) GetSession(sessionID string) (domain.SessionDetail, error) {
+	var detail domain.SessionDetail
+
+	session, err := s.rep.TradingSession.Find(sessionID)
+	if err != nil {
+		return detail, err
+	}
+
+	if session.ID == "" || session.Status == domain.SessionDraft {
+		return detail, ErrSessionNotFound
+	}
+
+	bids, err := s.rep.TradingBid.CountBySession(sessionID)
+	if err != nil {
+		return detail, err
+	}
+
+	participants, err := s.rep.TradingBid.CountParticipants(sessionID)
+	if err != nil {
+		return detail, err
+	}
+
+	session.BidStep = session.StepPolicy()
+
+	detail.TradingSession = session
+	detail.Summary = s.summarize(session, bids, participants, time.Now())
+
+	return detail, nil
+}
+
+func (s *Service) summarize(session domain.TradingSession, bids, participants int, now time.Time) domain.SessionSummary {
+	summary := domain.SessionSummary{
+		BestPrice:    session.BestPrice(),
+		Participants: participants,
+		Bids:         bids,
+		ServerTime:   now.UTC(),
+	}
+
+	if session.Type() == domain.AuctionDutch && session.IsOpen(now) {
+		summary.BestPrice = session.ClockPrice(now)
+	}
+
+	if session.LeaderID != "" {
+		summary.Leader = s.anonymize(session.ID, session.LeaderID)
+	}
+
+	if !isFinished(session.Status) && session.Status != domain.SessionCancelled && now.Before(session.Date.End) {
+		summary.TimeRemaining = int64(session.Date.End.Sub(now) / time.Second)
+	}
+
+	return summary
+}
+
+// anonymize returns an alias of the user that is stable within the session
+// but cannot be matched across sessions. The alias is keyed, so that it
+// cannot be computed from the IDs alone.
+func (s *Service) anonymize(sessionID, userID string) string {
+	mac := hmac.New(sha256.New, s.aliasKey)
+	mac.Write([]byte(sessionID + "/" + userID))
+
+	return "Участник " + hex.EncodeToString(mac.Sum(nil)[:3])
+}
+
+// privateActions are only delivered to the user the event is about.
+var privateActions = map[string]bool{
+	domain.EventActionProxyBid:       true,
+	domain.EventActionProxyExhausted: true,
+}
+
+// PublicEvent returns the event as it may be sent to the user and whether it
+// may be sent at all. Private events only reach the user they are about,
+// in other events the user is replaced by the alias used within the session.
+func (s *Service) PublicEvent(event domain.Event, userID string) (domain.Event, bool) {
+	if privateActions[event.Action] {
+		return event, event.GUID == userID
+	}
+
+	if event.GUID != "" {
+		event.GUID = s.anonymize(event.EventID, event.GUID)
+	}
+
+	return event, true
+}
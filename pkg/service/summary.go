@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+// The read model is updated once a change is committed. Its failures are
+// only logged, as the change stands anyway, and RepairSummaries catches up.
+
+// saveBid stores the bid and counts it in the read model of its session.
+func (s *Service) saveBid(bid domain.TradingBid) error {
+	if err := s.rep.TradingBid.Save(context.Background(), bid); err != nil {
+		return err
+	}
+
+	s.countBid(bid)
+
+	return nil
+}
+
+// countBid counts a stored bid in the read model of its session.
+func (s *Service) countBid(bid domain.TradingBid) {
+	s.refreshSummary(bid.TradingSessionID)
+
+	if err := s.rep.SessionSummary.RecordBid(bid.TradingSessionID, bid.Date); err != nil {
+		s.logger.Log("session", bid.TradingSessionID, "error", err)
+	}
+}
+
+// refreshSummary copies the current state of the session into its read model.
+func (s *Service) refreshSummary(sessionID string) {
+	session, err := s.rep.TradingSession.Find(sessionID)
+	if err != nil {
+		s.logger.Log("session", sessionID, "error", err)
+		return
+	}
+
+	if session.ID == "" {
+		return
+	}
+
+	s.syncSummary(session)
+}
+
+// syncSummary copies the session into its read model.
+func (s *Service) syncSummary(session domain.TradingSession) {
+	if err := s.saveSummary(session); err != nil {
+		s.logger.Log("session", session.ID, "error", err)
+	}
+}
+
+func (s *Service) saveSummary(session domain.TradingSession) error {
+	var leader string
+	if session.LeaderID != "" {
+		leader = s.anonymize(session.ID, session.LeaderID)
+	}
+
+	session.BidStep = session.StepPolicy()
+
+	return s.rep.SessionSummary.SaveSession(session, session.BestPrice(), leader)
+}
+
+// RepairSummaries rewrites the views that are missing or lag behind their
+// session and resets the bid counters that disagree with the bids, e.g. after
+// an update of the read model failed or on the first start against an
+// existing database.
+func (s *Service) RepairSummaries() error {
+	sessions, err := s.rep.TradingSession.FindAll()
+	if err != nil {
+		return err
+	}
+
+	found, err := s.rep.SessionSummary.FindAll()
+	if err != nil {
+		return err
+	}
+
+	views := make(map[string]domain.SessionView, len(found))
+	for _, view := range found {
+		views[view.ID] = view
+	}
+
+	stats, err := s.rep.TradingBid.Stats()
+	if err != nil {
+		return err
+	}
+
+	counted := make(map[string]domain.BidStats, len(stats))
+	for _, stat := range stats {
+		counted[stat.TradingSessionID] = stat
+	}
+
+	for _, session := range sessions {
+		view, ok := views[session.ID]
+
+		if !ok || view.Revision < session.Revision {
+			if err := s.saveSummary(session); err != nil {
+				return err
+			}
+		}
+
+		stat, ok := counted[session.ID]
+		if !ok || stat.Count == view.BidCount {
+			continue
+		}
+
+		if err := s.rep.SessionSummary.SetBidStats(stat); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RebuildSummaries regenerates the read model of every session from the
+// sessions and bids collections.
+func (s *Service) RebuildSummaries() error {
+	sessions, err := s.rep.TradingSession.FindAll()
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if err := s.saveSummary(session); err != nil {
+			return err
+		}
+	}
+
+	stats, err := s.rep.TradingBid.Stats()
+	if err != nil {
+		return err
+	}
+
+	for _, stat := range stats {
+		if err := s.rep.SessionSummary.SetBidStats(stat); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
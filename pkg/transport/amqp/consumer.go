@@ -0,0 +1,115 @@
+package amqp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/streadway/amqp"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+	"github.com/Bipolar-Penguin/bff-website/pkg/pubsub"
+)
+
+// consumeRetry is how long Consume waits before consuming again, the
+// connection itself is redialed with backoff.
+const consumeRetry = time.Second
+
+// Consume hands the events published by every replica to publisher until ctx
+// is cancelled. Each replica reads the whole exchange through its own
+// temporary queue, so events published by this replica or under several
+// routing keys come more than once and publisher is expected to drop repeats.
+func (b *RabbitBroker) Consume(ctx context.Context, publisher pubsub.Publisher) {
+	for {
+		err := b.consume(ctx, publisher)
+		if errors.Is(err, ErrClosed) {
+			return
+		}
+
+		// ErrUnavailable was already logged by the failed dial
+		if err != nil && !errors.Is(err, ErrUnavailable) {
+			b.logger.Log("error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(consumeRetry):
+		}
+	}
+}
+
+// consume reads the events until the connection breaks or ctx is cancelled.
+func (b *RabbitBroker) consume(ctx context.Context, publisher pubsub.Publisher) error {
+	conn, err := b.connection()
+	if err != nil {
+		return err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	if err := b.declare(ch); err != nil {
+		return err
+	}
+
+	q, err := ch.QueueDeclare(
+		"",    // name, generated by the broker
+		false, // durable
+		true,  // delete when unused
+		true,  // exclusive
+		false, // no-wait
+		nil,   // arguments
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := ch.QueueBind(q.Name, "#", b.exchange, false, nil); err != nil {
+		return err
+	}
+
+	deliveries, err := ch.Consume(
+		q.Name, // queue
+		"",     // consumer
+		true,   // auto-ack, live events are not worth redelivering
+		true,   // exclusive
+		false,  // no-local
+		false,  // no-wait
+		nil,    // args
+	)
+	if err != nil {
+		return err
+	}
+
+	closed := ch.NotifyClose(make(chan *amqp.Error, 1))
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-closed:
+			if err == nil {
+				return nil
+			}
+			return err
+		case d, ok := <-deliveries:
+			if !ok {
+				return nil
+			}
+
+			var event domain.Event
+
+			if err := json.Unmarshal(d.Body, &event); err != nil {
+				b.logger.Log("error", err)
+				continue
+			}
+
+			publisher.PublishEvent(event)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-kit/log"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+	"github.com/Bipolar-Penguin/bff-website/pkg/repository/lease"
+	"github.com/Bipolar-Penguin/bff-website/pkg/repository/outbox"
+)
+
+const (
+	relayLeaseName = "outbox_relay"
+	relayBatchSize = 100
+	// relayMaxAttempts is how many times a message is tried before it is
+	// moved to the dead-letter store.
+	relayMaxAttempts = 20
+	relayMaxBackoff  = 5 * time.Minute
+)
+
+// Relay sends the events of the outbox to the broker and removes them once
+// the broker confirms them, so every event is delivered at least once. A
+// message the broker keeps failing is retried with backoff and finally
+// dead-lettered. Only the replica holding the relay lease does the work.
+type Relay struct {
+	broker *RabbitBroker
+	outbox outbox.Repository
+	runner *lease.Runner
+	logger log.Logger
+}
+
+func NewRelay(broker *RabbitBroker, outbox outbox.Repository, leases lease.Repository, holder string, interval time.Duration, logger log.Logger) *Relay {
+	return &Relay{broker, outbox, lease.NewRunner(leases, relayLeaseName, holder, interval, logger), logger}
+}
+
+// Run drains the outbox until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	r.runner.Run(ctx, func(now time.Time, _ int64) {
+		r.drain(now)
+	})
+}
+
+// drain relays the due messages, the oldest first. It stops at the first
+// failure and leaves the rest for the following ticks.
+func (r *Relay) drain(now time.Time) {
+	messages, err := r.outbox.FindDue(now, relayBatchSize)
+	if err != nil {
+		r.logger.Log("error", err)
+		return
+	}
+
+	for _, message := range messages {
+		err := r.broker.Publish(message.Event)
+
+		// while the broker is unreachable no message is to blame
+		if errors.Is(err, ErrUnavailable) {
+			return
+		}
+
+		if err != nil {
+			r.fail(message, err, now)
+			return
+		}
+
+		if err := r.outbox.Delete(message.ID); err != nil {
+			// the message is sent again, consumers drop it by its ID
+			r.logger.Log("error", err)
+			return
+		}
+	}
+}
+
+// fail postpones the message or dead-letters it after too many attempts.
+func (r *Relay) fail(message domain.OutboxMessage, cause error, now time.Time) {
+	attempts := message.Attempts + 1
+
+	if attempts >= relayMaxAttempts {
+		r.logger.Log("event", message.ID, "dead_letter", true, "error", cause)
+
+		if err := r.outbox.DeadLetter(message, cause.Error()); err != nil {
+			r.logger.Log("error", err)
+		}
+		return
+	}
+
+	r.logger.Log("event", message.ID, "attempts", attempts, "error", cause)
+
+	if err := r.outbox.Retry(message.ID, now.Add(retryBackoff(attempts)), cause.Error()); err != nil {
+		r.logger.Log("error", err)
+	}
+}
+
+// retryBackoff doubles the wait after every failed attempt.
+func retryBackoff(attempts int) time.Duration {
+	backoff := minBackoff << (attempts - 1)
+	if backoff <= 0 || backoff > relayMaxBackoff {
+		return relayMaxBackoff
+	}
+
+	return backoff
+}
@@ -0,0 +1,28 @@
+package amqp
+
+import "github.com/Bipolar-Penguin/bff-website/pkg/domain"
+
+// routingKeys returns the keys the event is published under:
+// session.<id>.bid for bids, session.<id>.status for other changes of the
+// session and user.<id>.notification for events addressed to a user. The
+// awards concern both the session and the winner.
+func routingKeys(event domain.Event) []string {
+	switch event.Action {
+	case domain.EventActionUpdate, domain.EventActionAccepted:
+		return []string{sessionKey(event.EventID, "bid")}
+	case domain.EventActionAwarded:
+		return []string{sessionKey(event.EventID, "status"), userKey(event.GUID)}
+	case domain.EventActionProxyBid, domain.EventActionProxyExhausted:
+		return []string{userKey(event.GUID)}
+	default:
+		return []string{sessionKey(event.EventID, "status")}
+	}
+}
+
+func sessionKey(sessionID, kind string) string {
+	return "session." + sessionID + "." + kind
+}
+
+func userKey(userID string) string {
+	return "user." + userID + ".notification"
+}
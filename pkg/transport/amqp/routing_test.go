@@ -0,0 +1,32 @@
+package amqp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+)
+
+func TestRoutingKeys(t *testing.T) {
+	tests := []struct {
+		action string
+		want   []string
+	}{
+		{domain.EventActionUpdate, []string{"session.s1.bid"}},
+		{domain.EventActionAccepted, []string{"session.s1.bid"}},
+		{domain.EventActionExtended, []string{"session.s1.status"}},
+		{domain.EventActionStatus, []string{"session.s1.status"}},
+		{domain.EventActionAwarded, []string{"session.s1.status", "user.u1.notification"}},
+		{domain.EventActionProxyBid, []string{"user.u1.notification"}},
+		{domain.EventActionProxyExhausted, []string{"user.u1.notification"}},
+		{"unknown", []string{"session.s1.status"}},
+	}
+
+	for _, tt := range tests {
+		event := domain.Event{Action: tt.action, EventID: "s1", GUID: "u1"}
+
+		if got := routingKeys(event); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("routingKeys(%s) = %v, want %v", tt.action, got, tt.want)
+		}
+	}
+}
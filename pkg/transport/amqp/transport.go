@@ -2,7 +2,9 @@ package amqp
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/go-kit/log"
@@ -11,55 +13,251 @@ import (
 	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
 )
 
+const (
+	// channelPoolSize is how many idle channels are kept open.
+	channelPoolSize = 8
+	// confirmTimeout is how long Publish waits for the broker to confirm.
+	confirmTimeout = 5 * time.Second
+
+	minBackoff = time.Second
+	maxBackoff = 30 * time.Second
+)
+
+var (
+	// ErrUnavailable is returned while the broker is unreachable.
+	ErrUnavailable = errors.New("rabbitmq is unavailable")
+	// ErrNotConfirmed is returned when the broker rejected the event or did not
+	// confirm it in time.
+	ErrNotConfirmed = errors.New("rabbitmq did not confirm the event")
+	// ErrClosed is returned after the broker was closed.
+	ErrClosed = errors.New("rabbitmq broker is closed")
+)
+
+// RabbitBroker publishes events to a topic exchange over one long-lived
+// connection, see routingKeys for the keys. The connection is dialed on first
+// use and redialed after it breaks, waiting longer after each failed attempt.
+// Publishing is done on a pool of channels in confirm mode.
 type RabbitBroker struct {
 	connString string
+	exchange   string
 	logger     log.Logger
+
+	mu       sync.Mutex
+	conn     *amqp.Connection
+	closed   bool
+	backoff  time.Duration
+	nextDial time.Time
+
+	channels chan *publishChannel
+}
+
+// publishChannel is a channel in confirm mode with its confirmations.
+type publishChannel struct {
+	conn     *amqp.Connection
+	ch       *amqp.Channel
+	confirms chan amqp.Confirmation
 }
 
-func NewRabbitBroker(connString string, logger log.Logger) *RabbitBroker {
-	return &RabbitBroker{connString, logger}
+func NewRabbitBroker(connString, exchange string, logger log.Logger) *RabbitBroker {
+	return &RabbitBroker{
+		connString: connString,
+		exchange:   exchange,
+		logger:     logger,
+		channels:   make(chan *publishChannel, channelPoolSize),
+	}
 }
 
+// PublishEvent publishes the event and logs whether it was delivered. It
+// never blocks for longer than confirmTimeout plus a single dial.
 func (b *RabbitBroker) PublishEvent(event domain.Event) {
-	b.logger.Log("event", fmt.Sprintf("%v", event))
+	if err := b.Publish(event); err != nil {
+		b.logger.Log("event", event.ID, "action", event.Action, "error", err)
+		return
+	}
 
+	b.logger.Log("event", event.ID, "action", event.Action, "delivered", true)
+}
+
+// Publish publishes the event under each of its routing keys and waits until
+// the broker confirms it.
+func (b *RabbitBroker) Publish(event domain.Event) error {
 	jsonBody, err := json.Marshal(event)
 	if err != nil {
-		b.logger.Log("error", err)
+		return err
 	}
 
-	conn, err := amqp.Dial(b.connString)
+	pc, err := b.channel()
 	if err != nil {
-		b.logger.Log("error", err)
-		time.Sleep(5 * time.Second)
+		return err
 	}
-	defer conn.Close()
 
-	ch, err := conn.Channel()
-	if err != nil {
-		b.logger.Log("error", err)
-		time.Sleep(5 * time.Second)
+	for _, key := range routingKeys(event) {
+		if err := pc.publish(b.exchange, key, event.ID, jsonBody); err != nil {
+			// the channel may be broken or hold a late confirmation
+			pc.ch.Close()
+			return err
+		}
 	}
 
-	_, err = ch.QueueDeclare(
-		"trading_session.update", // name
-		false,                    // durable
-		true,                     // delete when unused
-		false,                    // exclusive
-		false,                    // no-wait
-		map[string]interface{}{"x-message-ttl": 10000}, // arguments
-	)
+	b.release(pc)
+
+	return nil
+}
+
+// Close closes the connection, publishing fails from then on.
+func (b *RabbitBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+
+	if b.conn == nil {
+		return nil
+	}
 
-	err = ch.Publish(
-		"",                       // exchange
-		"trading_session.update", // routing key
-		false,                    // mandatory
-		false,                    // immediate
+	return b.conn.Close()
+}
+
+// publish publishes a message and waits for its confirmation.
+func (pc *publishChannel) publish(exchange, key, id string, body []byte) error {
+	err := pc.ch.Publish(
+		exchange, // exchange
+		key,      // routing key
+		false,    // mandatory
+		false,    // immediate
 		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        []byte(jsonBody),
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			MessageId:    id,
+			Body:         body,
 		})
 	if err != nil {
-		b.logger.Log("error", err)
+		return err
+	}
+
+	select {
+	case confirm, ok := <-pc.confirms:
+		if !ok || !confirm.Ack {
+			return ErrNotConfirmed
+		}
+		return nil
+	case <-time.After(confirmTimeout):
+		return ErrNotConfirmed
+	}
+}
+
+// channel takes an idle channel of the current connection from the pool or
+// opens a new one.
+func (b *RabbitBroker) channel() (*publishChannel, error) {
+	conn, err := b.connection()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		select {
+		case pc := <-b.channels:
+			if pc.conn == conn {
+				return pc, nil
+			}
+			// left over from a broken connection
+			pc.ch.Close()
+		default:
+			return b.openPublishChannel(conn)
+		}
+	}
+}
+
+// release puts the channel back to the pool or closes it if the pool is full.
+func (b *RabbitBroker) release(pc *publishChannel) {
+	select {
+	case b.channels <- pc:
+	default:
+		pc.ch.Close()
 	}
 }
+
+func (b *RabbitBroker) openPublishChannel(conn *amqp.Connection) (*publishChannel, error) {
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.declare(ch); err != nil {
+		ch.Close()
+		return nil, err
+	}
+
+	if err := ch.Confirm(false); err != nil {
+		ch.Close()
+		return nil, err
+	}
+
+	return &publishChannel{
+		conn:     conn,
+		ch:       ch,
+		confirms: ch.NotifyPublish(make(chan amqp.Confirmation, 1)),
+	}, nil
+}
+
+// connection returns the open connection, dialing a new one if the previous
+// one broke and the backoff since the last failed attempt has passed.
+func (b *RabbitBroker) connection() (*amqp.Connection, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+
+	if b.conn != nil && !b.conn.IsClosed() {
+		return b.conn, nil
+	}
+
+	if time.Now().Before(b.nextDial) {
+		return nil, ErrUnavailable
+	}
+
+	conn, err := amqp.Dial(b.connString)
+	if err != nil {
+		b.backoff = nextBackoff(b.backoff)
+		b.nextDial = time.Now().Add(b.backoff)
+
+		b.logger.Log("error", err, "retry_in", b.backoff)
+
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	b.conn = conn
+	b.backoff = 0
+	b.nextDial = time.Time{}
+
+	return conn, nil
+}
+
+func nextBackoff(backoff time.Duration) time.Duration {
+	if backoff == 0 {
+		return minBackoff
+	}
+
+	backoff *= 2
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+
+	return backoff
+}
+
+// declare declares the exchange the events are published to. Queues are up
+// to the consumers.
+func (b *RabbitBroker) declare(ch *amqp.Channel) error {
+	return ch.ExchangeDeclare(
+		b.exchange, // name
+		"topic",    // type
+		true,       // durable
+		false,      // auto-deleted
+		false,      // internal
+		false,      // no-wait
+		nil,        // arguments
+	)
+}
@@ -0,0 +1,113 @@
+package httptransport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+	"github.com/Bipolar-Penguin/bff-website/pkg/pubsub"
+)
+
+const (
+	// sseHeartbeat keeps proxies from closing idle streams.
+	sseHeartbeat = 30 * time.Second
+	// sseBuffer is how many events a client may lag behind before the stream
+	// is closed, the client then resumes with Last-Event-ID.
+	sseBuffer = 64
+	sseRetry  = 3 * time.Second
+)
+
+var errStreamingUnsupported = errors.New("streaming unsupported")
+
+// streamEvents sends the events of a session, or of every session when no
+// session is given, as Server-Sent Events. The token is optional, it is only
+// needed to receive the private events of the user.
+func (s *HTTPServer) streamEvents(rw http.ResponseWriter, r *http.Request) {
+	userID, err := s.authorizeOptional(r)
+	if err != nil {
+		s.abortWithError(rw, http.StatusUnauthorized, err)
+		return
+	}
+
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		s.abortWithError(rw, http.StatusInternalServerError, errStreamingUnsupported)
+		return
+	}
+
+	topic := pubsub.TopicAll
+	if sessionID, ok := mux.Vars(r)["session_id"]; ok {
+		topic = sessionID
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+
+	sub, missed := s.events.SubscribeAfter(sseBuffer, lastID, topic)
+	defer sub.Close()
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.Header().Set("Connection", "keep-alive")
+	rw.Header().Set("X-Accel-Buffering", "no")
+	rw.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(rw, "retry: %d\n\n", sseRetry.Milliseconds())
+
+	for _, event := range missed {
+		if err := s.writeSSE(rw, event, userID); err != nil {
+			return
+		}
+	}
+
+	flusher.Flush()
+
+	ticker := time.NewTicker(sseHeartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event := <-sub.Events():
+			if err := s.writeSSE(rw, event, userID); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if _, err := fmt.Fprint(rw, ": ping\n\n"); err != nil {
+				return
+			}
+		case <-sub.Done():
+			return
+		case <-r.Context().Done():
+			return
+		case <-s.closing:
+			return
+		}
+
+		flusher.Flush()
+	}
+}
+
+// writeSSE writes the event as a message with the event ID, so that the
+// client can resume after it.
+func (s *HTTPServer) writeSSE(rw http.ResponseWriter, event domain.Event, userID string) error {
+	event, ok := s.service.PublicEvent(event, userID)
+	if !ok {
+		return nil
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(rw, "id: %s\ndata: %s\n\n", event.ID, data)
+
+	return err
+}
@@ -6,12 +6,16 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/go-kit/log"
 	"github.com/gorilla/mux"
 
 	"github.com/Bipolar-Penguin/bff-website/pkg/domain"
+	"github.com/Bipolar-Penguin/bff-website/pkg/pubsub"
+	"github.com/Bipolar-Penguin/bff-website/pkg/repository/session_summary"
 	"github.com/Bipolar-Penguin/bff-website/pkg/service"
 )
 
@@ -28,6 +32,59 @@ type authRequest struct {
 	UserID string `json:"user_id"`
 }
 
+type bidRequest struct {
+	LotID string `json:"lot_id"`
+	Price int    `json:"price"`
+}
+
+type sessionRequest struct {
+	Title       string               `json:"title"`
+	Description string               `json:"description"`
+	AuctionType domain.AuctionType   `json:"auction_type"`
+	MaxPrice    int                  `json:"max_price"`
+	MinPrice    int                  `json:"min_price"`
+	BidStep     domain.BidStep       `json:"bid_step"`
+	Lots        []domain.Lot         `json:"lots"`
+	Extension   domain.ExtensionRule `json:"extension"`
+	Date        struct {
+		Start time.Time `json:"start"`
+		End   time.Time `json:"end"`
+	} `json:"date"`
+	ImageURLs []string `json:"image_urls"`
+	Region    string   `json:"region"`
+	Version   int      `json:"version"`
+}
+
+func (req sessionRequest) toSession(sessionID string) domain.TradingSession {
+	var session domain.TradingSession
+
+	session.ID = sessionID
+	session.Title = req.Title
+	session.Description = req.Description
+	session.AuctionType = req.AuctionType
+	session.MaxPrice = req.MaxPrice
+	session.MinPrice = req.MinPrice
+	session.BidStep = req.BidStep
+	session.Lots = req.Lots
+	session.Extension = req.Extension
+	session.Date.Start = req.Date.Start
+	session.Date.End = req.Date.End
+	session.ImageURLs = req.ImageURLs
+	session.Region = req.Region
+	session.Version = req.Version
+
+	return session
+}
+
+type sessionsResponse struct {
+	Sessions   []domain.SessionView `json:"sessions"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
+
+type proxyBidRequest struct {
+	Floor int `json:"floor"`
+}
+
 type HTTPServer struct {
 	port        int
 	Logger      log.Logger
@@ -35,19 +92,26 @@ type HTTPServer struct {
 	Router      *mux.Router
 	ReadTimeout time.Duration
 	service     *service.Service
+	events      *pubsub.Hub
+	// closing is closed on shutdown to end the event streams, which would
+	// otherwise keep the server waiting
+	closing chan struct{}
 	http.Server
 }
 
 type errorResponse struct {
 	Message string `json:"error"`
+	Code    string `json:"code,omitempty"`
 }
 
-func NewHttpServer(port int, logger log.Logger, service *service.Service) *HTTPServer {
+func NewHttpServer(port int, logger log.Logger, service *service.Service, events *pubsub.Hub) *HTTPServer {
 	srv := &HTTPServer{
 		port:    port,
 		Logger:  logger,
 		Router:  mux.NewRouter(),
 		service: service,
+		events:  events,
+		closing: make(chan struct{}),
 	}
 	srv.configureRouter()
 
@@ -65,7 +129,7 @@ func (s *HTTPServer) corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
 		rw.Header().Set("Access-Control-Allow-Origin", "*")
 		rw.Header().Set("Access-Control-Allow-Credentials", "true")
-		rw.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		rw.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, Last-Event-ID")
 		rw.Header().Set("Access-Control-Allow-Methods", "POST,HEAD,PATCH,OPTIONS,GET,PUT,DELETE")
 		if r.Method == "OPTIONS" {
 			rw.WriteHeader(http.StatusNoContent)
@@ -92,6 +156,37 @@ func (s *HTTPServer) authenticateUser(next http.Handler) http.Handler {
 	})
 }
 
+// authorize returns the ID of the user the request is authenticated as. The
+// token may also come in the token query parameter, as browsers cannot set
+// headers on WebSocket and EventSource requests.
+func (s *HTTPServer) authorize(r *http.Request) (string, error) {
+	token := r.Header.Get(authHeader)
+	if token == "" {
+		token = r.URL.Query().Get("token")
+	}
+
+	userID, err := s.service.Authenticate(token)
+	if err != nil {
+		return "", err
+	}
+
+	if userID == "" {
+		return "", errors.New("not authorized")
+	}
+
+	return userID, nil
+}
+
+// authorizeOptional is authorize for requests the token is optional for, it
+// returns an empty user ID if there is no token at all.
+func (s *HTTPServer) authorizeOptional(r *http.Request) (string, error) {
+	if r.Header.Get(authHeader) == "" && r.URL.Query().Get("token") == "" {
+		return "", nil
+	}
+
+	return s.authorize(r)
+}
+
 func (s *HTTPServer) respond(rw http.ResponseWriter, r *http.Request, code int, data interface{}) {
 	var jsonBody []byte
 
@@ -107,16 +202,45 @@ func (s *HTTPServer) respond(rw http.ResponseWriter, r *http.Request, code int,
 	rw.Write(jsonBody)
 }
 
+// abortWithServiceError maps errors of the session management features to
+// status codes.
+func (s *HTTPServer) abortWithServiceError(rw http.ResponseWriter, err error) {
+	var validationErr *service.ValidationError
+	var transitionErr *service.TransitionError
+
+	switch {
+	case errors.Is(err, service.ErrSessionNotFound):
+		s.abortWithError(rw, http.StatusNotFound, err)
+	case errors.Is(err, service.ErrNotOwner):
+		s.abortWithError(rw, http.StatusForbidden, err)
+	case errors.Is(err, service.ErrSessionChanged):
+		s.abortWithError(rw, http.StatusConflict, err)
+	case errors.As(err, &validationErr):
+		s.abortWithError(rw, http.StatusUnprocessableEntity, err)
+	case errors.As(err, &transitionErr):
+		s.abortWithError(rw, http.StatusConflict, err)
+	default:
+		s.abortWithError(rw, http.StatusInternalServerError, err)
+	}
+}
+
 func (s *HTTPServer) abortWithError(rw http.ResponseWriter, code int, err error) {
 	var res errorResponse
 
 	rw.WriteHeader(code)
 
 	res.Message = err.Error()
+
+	var bidErr *service.BidError
+	if errors.As(err, &bidErr) {
+		res.Code = bidErr.Code
+	}
+
 	json.NewEncoder(rw).Encode(res)
 }
 
 func (s *HTTPServer) Shutdown(ctx context.Context) error {
+	close(s.closing)
 	return s.Server.Shutdown(ctx)
 }
 
@@ -143,6 +267,9 @@ func (s *HTTPServer) configureRouter() {
 	s.Router.Use(s.corsMiddleware)
 	//s.Router.Use(s.authenticateUser)
 
+	s.Router.HandleFunc("/ws", s.serveWebSocket).Methods(http.MethodGet)
+	s.Router.HandleFunc("/events", s.streamEvents).Methods(http.MethodGet, http.MethodOptions)
+
 	user := s.Router.PathPrefix("/user").Subrouter()
 	{
 		user.HandleFunc("", s.saveUser).Methods(http.MethodPost, http.MethodOptions)
@@ -152,8 +279,18 @@ func (s *HTTPServer) configureRouter() {
 	sessions := s.Router.PathPrefix("/session").Subrouter()
 	{
 		sessions.HandleFunc("", s.getSessions).Methods(http.MethodGet, http.MethodOptions)
-		sessions.HandleFunc("/{session_id}", s.getBids).Methods(http.MethodGet, http.MethodOptions)
+		sessions.HandleFunc("", s.createSession).Methods(http.MethodPost, http.MethodOptions)
+		sessions.HandleFunc("/search", s.searchSessions).Methods(http.MethodGet, http.MethodOptions)
+		sessions.HandleFunc("/{session_id}", s.updateSession).Methods(http.MethodPut, http.MethodOptions)
+		sessions.HandleFunc("/{session_id}/publish", s.publishSession).Methods(http.MethodPost, http.MethodOptions)
+		sessions.HandleFunc("/{session_id}/cancel", s.cancelSession).Methods(http.MethodPost, http.MethodOptions)
+		sessions.HandleFunc("/{session_id}", s.getSession).Methods(http.MethodGet, http.MethodOptions)
+		sessions.HandleFunc("/{session_id}/bids", s.getBids).Methods(http.MethodGet, http.MethodOptions)
+		sessions.HandleFunc("/{session_id}/events", s.streamEvents).Methods(http.MethodGet, http.MethodOptions)
 		sessions.HandleFunc("/{session_id}", s.makeBid).Methods(http.MethodPost, http.MethodOptions)
+		sessions.HandleFunc("/{session_id}/proxy", s.makeProxyBid).Methods(http.MethodPost, http.MethodOptions)
+		sessions.HandleFunc("/{session_id}/accept", s.acceptSession).Methods(http.MethodPost, http.MethodOptions)
+		sessions.HandleFunc("/{session_id}/awards", s.getAwards).Methods(http.MethodGet, http.MethodOptions)
 	}
 }
 
@@ -167,7 +304,24 @@ func (s *HTTPServer) getBids(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	bids, err := s.service.GetTradingBids(sessionID)
+	offset, err := parseInt(r.URL.Query(), "offset")
+	if err != nil {
+		s.abortWithError(rw, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	limit, err := parseInt(r.URL.Query(), "limit")
+	if err != nil {
+		s.abortWithError(rw, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	bids, err := s.service.GetTradingBids(sessionID, offset, limit)
+	if errors.Is(err, service.ErrBidsSealed) {
+		s.abortWithError(rw, http.StatusForbidden, err)
+		return
+	}
+
 	if err != nil {
 		s.abortWithError(rw, http.StatusInternalServerError, err)
 		return
@@ -198,7 +352,25 @@ func (s *HTTPServer) makeBid(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.service.MakeTradingBid(sessionID, userID); err != nil {
+	var req bidRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.abortWithError(rw, http.StatusUnprocessableEntity, errUnprocessableEntity)
+		return
+	}
+
+	if err := s.service.MakeTradingBid(sessionID, req.LotID, userID, req.Price); err != nil {
+		var bidErr *service.BidError
+		if errors.As(err, &bidErr) && bidErr.Code == service.BidErrOutbid {
+			s.abortWithError(rw, http.StatusConflict, err)
+			return
+		}
+
+		if errors.As(err, &bidErr) {
+			s.abortWithError(rw, http.StatusUnprocessableEntity, err)
+			return
+		}
+
 		s.abortWithError(rw, http.StatusInternalServerError, err)
 		return
 	}
@@ -206,15 +378,343 @@ func (s *HTTPServer) makeBid(rw http.ResponseWriter, r *http.Request) {
 	s.respond(rw, r, http.StatusOK, map[string]string{"status": "bid done"})
 }
 
+func (s *HTTPServer) makeProxyBid(rw http.ResponseWriter, r *http.Request) {
+	authToken := r.Header.Get(authHeader)
+
+	userID, err := s.service.Authenticate(authToken)
+	if err != nil {
+		s.abortWithError(rw, http.StatusUnauthorized, err)
+		return
+	}
+
+	if userID == "" {
+		s.abortWithError(rw, http.StatusUnauthorized, errors.New("not authorized"))
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	sessionID, ok := vars["session_id"]
+	if !ok {
+		s.abortWithError(rw, http.StatusInternalServerError, errors.New("session id was not provided"))
+		return
+	}
+
+	var req proxyBidRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.abortWithError(rw, http.StatusUnprocessableEntity, errUnprocessableEntity)
+		return
+	}
+
+	proxy, err := s.service.RegisterProxyBid(sessionID, userID, req.Floor)
+	if err != nil {
+		var bidErr *service.BidError
+		if errors.As(err, &bidErr) {
+			s.abortWithError(rw, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		s.abortWithError(rw, http.StatusInternalServerError, err)
+		return
+	}
+
+	s.respond(rw, r, http.StatusOK, proxy)
+}
+
+func (s *HTTPServer) acceptSession(rw http.ResponseWriter, r *http.Request) {
+	authToken := r.Header.Get(authHeader)
+
+	userID, err := s.service.Authenticate(authToken)
+	if err != nil {
+		s.abortWithError(rw, http.StatusUnauthorized, err)
+		return
+	}
+
+	if userID == "" {
+		s.abortWithError(rw, http.StatusUnauthorized, errors.New("not authorized"))
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	sessionID, ok := vars["session_id"]
+	if !ok {
+		s.abortWithError(rw, http.StatusInternalServerError, errors.New("session id was not provided"))
+		return
+	}
+
+	bid, err := s.service.AcceptTradingSession(sessionID, userID)
+	if err != nil {
+		var bidErr *service.BidError
+		if errors.As(err, &bidErr) && bidErr.Code == service.BidErrOutbid {
+			s.abortWithError(rw, http.StatusConflict, err)
+			return
+		}
+
+		if errors.As(err, &bidErr) {
+			s.abortWithError(rw, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		s.abortWithError(rw, http.StatusInternalServerError, err)
+		return
+	}
+
+	s.respond(rw, r, http.StatusOK, bid)
+}
+
 // TradingSessions features
+func (s *HTTPServer) getSession(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	sessionID, ok := vars["session_id"]
+	if !ok {
+		s.abortWithError(rw, http.StatusInternalServerError, errors.New("session id was not provided"))
+		return
+	}
+
+	session, err := s.service.GetSession(sessionID)
+	if err != nil {
+		s.abortWithServiceError(rw, err)
+		return
+	}
+
+	s.respond(rw, r, http.StatusOK, session)
+}
+
 func (s *HTTPServer) getSessions(rw http.ResponseWriter, r *http.Request) {
-	sessions, err := s.service.GetSessions()
+	filter, err := parseSessionFilter(r.URL.Query())
+	if err != nil {
+		s.abortWithError(rw, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	// the listing is public, the user only matters for their own drafts
+	userID, _ := s.authorize(r)
+
+	sessions, next, err := s.service.GetSessions(userID, filter)
+	if errors.Is(err, session_summary.ErrInvalidCursor) {
+		s.abortWithError(rw, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	if err != nil {
+		s.abortWithServiceError(rw, err)
+		return
+	}
+
+	if sessions == nil {
+		sessions = []domain.SessionView{}
+	}
+
+	s.respond(rw, r, http.StatusOK, sessionsResponse{Sessions: sessions, NextCursor: next})
+}
+
+func (s *HTTPServer) searchSessions(rw http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		s.abortWithError(rw, http.StatusUnprocessableEntity, errors.New("q must not be empty"))
+		return
+	}
+
+	limit, err := parseInt(r.URL.Query(), "limit")
+	if err != nil {
+		s.abortWithError(rw, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	hits, err := s.service.SearchSessions(query, limit)
 	if err != nil {
 		s.abortWithError(rw, http.StatusInternalServerError, err)
 		return
 	}
 
-	s.respond(rw, r, http.StatusOK, sessions)
+	s.respond(rw, r, http.StatusOK, hits)
+}
+
+// parseSessionFilter reads the GET /session query parameters: status (may be
+// repeated), region, owner, q, price_min, price_max, date_from, date_to
+// (RFC 3339), sort (end_date, price or created), order (asc or desc), cursor
+// and limit.
+func parseSessionFilter(query url.Values) (session_summary.Filter, error) {
+	var filter session_summary.Filter
+
+	var err error
+
+	for _, status := range query["status"] {
+		filter.Statuses = append(filter.Statuses, domain.SessionStatus(status))
+	}
+
+	filter.Region = query.Get("region")
+	filter.UserID = query.Get("owner")
+	filter.Query = query.Get("q")
+	filter.Cursor = query.Get("cursor")
+
+	if filter.MinPrice, err = parseInt(query, "price_min"); err != nil {
+		return filter, err
+	}
+
+	if filter.MaxPrice, err = parseInt(query, "price_max"); err != nil {
+		return filter, err
+	}
+
+	if filter.Limit, err = parseInt(query, "limit"); err != nil {
+		return filter, err
+	}
+
+	if filter.From, err = parseTime(query, "date_from"); err != nil {
+		return filter, err
+	}
+
+	if filter.To, err = parseTime(query, "date_to"); err != nil {
+		return filter, err
+	}
+
+	switch sort := query.Get("sort"); sort {
+	case "", session_summary.SortEndDate, session_summary.SortPrice, session_summary.SortCreated:
+		filter.Sort = sort
+	default:
+		return filter, fmt.Errorf("unknown sort %q", sort)
+	}
+
+	switch order := query.Get("order"); order {
+	case "", "asc":
+	case "desc":
+		filter.Desc = true
+	default:
+		return filter, fmt.Errorf("unknown order %q", order)
+	}
+
+	return filter, nil
+}
+
+func parseInt(query url.Values, key string) (int, error) {
+	value := query.Get(key)
+	if value == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer", key)
+	}
+
+	return n, nil
+}
+
+func parseTime(query url.Values, key string) (time.Time, error) {
+	value := query.Get(key)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be an RFC 3339 time", key)
+	}
+
+	return t, nil
+}
+
+func (s *HTTPServer) getAwards(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	sessionID, ok := vars["session_id"]
+	if !ok {
+		s.abortWithError(rw, http.StatusInternalServerError, errors.New("session id was not provided"))
+		return
+	}
+
+	userID, err := s.authorizeOptional(r)
+	if err != nil {
+		s.abortWithError(rw, http.StatusUnauthorized, err)
+		return
+	}
+
+	awards, err := s.service.GetAwards(sessionID, userID)
+	if err != nil {
+		s.abortWithError(rw, http.StatusInternalServerError, err)
+		return
+	}
+
+	s.respond(rw, r, http.StatusOK, awards)
+}
+
+func (s *HTTPServer) createSession(rw http.ResponseWriter, r *http.Request) {
+	userID, err := s.authorize(r)
+	if err != nil {
+		s.abortWithError(rw, http.StatusUnauthorized, err)
+		return
+	}
+
+	var req sessionRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.abortWithError(rw, http.StatusUnprocessableEntity, errUnprocessableEntity)
+		return
+	}
+
+	session, err := s.service.CreateSession(userID, req.toSession(""))
+	if err != nil {
+		s.abortWithServiceError(rw, err)
+		return
+	}
+
+	s.respond(rw, r, http.StatusCreated, session)
+}
+
+func (s *HTTPServer) updateSession(rw http.ResponseWriter, r *http.Request) {
+	userID, err := s.authorize(r)
+	if err != nil {
+		s.abortWithError(rw, http.StatusUnauthorized, err)
+		return
+	}
+
+	var req sessionRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.abortWithError(rw, http.StatusUnprocessableEntity, errUnprocessableEntity)
+		return
+	}
+
+	session, err := s.service.UpdateSession(userID, req.toSession(mux.Vars(r)["session_id"]))
+	if err != nil {
+		s.abortWithServiceError(rw, err)
+		return
+	}
+
+	s.respond(rw, r, http.StatusOK, session)
+}
+
+func (s *HTTPServer) publishSession(rw http.ResponseWriter, r *http.Request) {
+	userID, err := s.authorize(r)
+	if err != nil {
+		s.abortWithError(rw, http.StatusUnauthorized, err)
+		return
+	}
+
+	if err := s.service.PublishSession(userID, mux.Vars(r)["session_id"]); err != nil {
+		s.abortWithServiceError(rw, err)
+		return
+	}
+
+	s.respond(rw, r, http.StatusOK, map[string]string{"status": string(domain.SessionPublished)})
+}
+
+func (s *HTTPServer) cancelSession(rw http.ResponseWriter, r *http.Request) {
+	userID, err := s.authorize(r)
+	if err != nil {
+		s.abortWithError(rw, http.StatusUnauthorized, err)
+		return
+	}
+
+	if err := s.service.CancelSession(userID, mux.Vars(r)["session_id"]); err != nil {
+		s.abortWithServiceError(rw, err)
+		return
+	}
+
+	s.respond(rw, r, http.StatusOK, map[string]string{"status": string(domain.SessionCancelled)})
 }
 
 // User features
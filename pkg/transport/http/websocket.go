@@ -0,0 +1,120 @@
+package httptransport
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/Bipolar-Penguin/bff-website/pkg/pubsub"
+)
+
+const (
+	wsWriteWait  = 10 * time.Second
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = wsPongWait * 9 / 10
+	// wsBuffer is how many events a client may lag behind before it is
+	// disconnected as a slow consumer.
+	wsBuffer         = 64
+	wsMaxMessageSize = 4096
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	// origins are not restricted, same as in corsMiddleware
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// wsRequest is sent by clients to change the set of sessions they follow.
+type wsRequest struct {
+	Action   string   `json:"action"`
+	Sessions []string `json:"sessions"`
+}
+
+func (s *HTTPServer) serveWebSocket(rw http.ResponseWriter, r *http.Request) {
+	userID, err := s.authorize(r)
+	if err != nil {
+		s.abortWithError(rw, http.StatusUnauthorized, err)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(rw, r, nil)
+	if err != nil {
+		s.Logger.Log("error", err)
+		return
+	}
+
+	sub := s.events.Subscribe(wsBuffer)
+
+	go s.readWebSocket(conn, sub)
+	s.writeWebSocket(conn, sub, userID)
+}
+
+// readWebSocket handles subscribe and unsubscribe requests until the
+// connection breaks.
+func (s *HTTPServer) readWebSocket(conn *websocket.Conn, sub *pubsub.Subscription) {
+	defer sub.Close()
+
+	conn.SetReadLimit(wsMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		var req wsRequest
+
+		if err := conn.ReadJSON(&req); err != nil {
+			return
+		}
+
+		switch req.Action {
+		case "subscribe":
+			sub.Add(req.Sessions...)
+		case "unsubscribe":
+			sub.Remove(req.Sessions...)
+		}
+	}
+}
+
+// writeWebSocket sends events and pings until the subscription is done.
+func (s *HTTPServer) writeWebSocket(conn *websocket.Conn, sub *pubsub.Subscription, userID string) {
+	ticker := time.NewTicker(wsPingPeriod)
+
+	defer func() {
+		ticker.Stop()
+		sub.Close()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case event := <-sub.Events():
+			event, ok := s.service.PublicEvent(event, userID)
+			if !ok {
+				continue
+			}
+
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-sub.Done():
+			// the reader closes the subscription once the client is gone
+			if sub.Dropped() {
+				conn.WriteControl(
+					websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "slow consumer"),
+					time.Now().Add(wsWriteWait),
+				)
+			}
+			return
+		}
+	}
+}